@@ -0,0 +1,243 @@
+package webgo
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tagParam is the struct tag used to bind named URI parameters
+	tagParam = "param"
+	// tagQuery is the struct tag used to bind query string parameters
+	tagQuery = "query"
+)
+
+var (
+	// ErrBindTarget is returned when the value provided to Bind is not a pointer to a struct
+	ErrBindTarget = errors.New("bind target should be a non-nil pointer to a struct")
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+
+	// bindFieldsCache caches the list of bindable fields of a struct type
+	bindFieldsCache = sync.Map{}
+)
+
+// Validator is implemented by any type which can validate itself, it is called
+// by the Typed handler after binding the request
+type Validator interface {
+	Validate() error
+}
+
+type bindField struct {
+	index []int
+	tag   string
+	name  string
+}
+
+type bindFields struct {
+	params []bindField
+	query  []bindField
+}
+
+func fieldsToBind(t reflect.Type) *bindFields {
+	if bf, ok := bindFieldsCache.Load(t); ok {
+		return bf.(*bindFields)
+	}
+
+	bf := &bindFields{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported field
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup(tagParam); ok && name != "-" {
+			bf.params = append(bf.params, bindField{index: field.Index, tag: tagParam, name: name})
+		}
+
+		if name, ok := field.Tag.Lookup(tagQuery); ok && name != "-" {
+			bf.query = append(bf.query, bindField{index: field.Index, tag: tagQuery, name: name})
+		}
+	}
+
+	bindFieldsCache.Store(t, bf)
+	return bf
+}
+
+// Bind populates v, which should be a pointer to a struct, from the request. The body is decoded
+// if it is JSON, then named URI parameters are set to fields with the tag `param:"<name>"` and
+// query string parameters are set to fields with the tag `query:"<name>"`. URI & query parameters
+// override the values decoded from the body. Errors are returned as an HTTPError with status 400,
+// 415 if the content type of the body is not supported, or ErrRequestTooLarge if the body is larger
+// than its limit
+func Bind(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrBindTarget
+	}
+
+	err := bindBody(r, v)
+	if err != nil {
+		return err
+	}
+
+	rv = rv.Elem()
+	fields := fieldsToBind(rv.Type())
+
+	if len(fields.params) > 0 {
+		var params map[string]string
+		if cp := webgoContext(r); cp != nil {
+			params = cp.Params()
+		}
+		for _, f := range fields.params {
+			value, ok := params[f.name]
+			if !ok {
+				continue
+			}
+			err = bindValues(rv.FieldByIndex(f.index), []string{value})
+			if err != nil {
+				return bindError(f, err)
+			}
+		}
+	}
+
+	if len(fields.query) > 0 {
+		query := r.URL.Query()
+		for _, f := range fields.query {
+			values, ok := query[f.name]
+			if !ok {
+				continue
+			}
+			err = bindValues(rv.FieldByIndex(f.index), values)
+			if err != nil {
+				return bindError(f, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func bindBody(r *http.Request, v interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	ctype := r.Header.Get(HeaderContentType)
+	if ctype != "" {
+		mtype, _, err := mime.ParseMediaType(ctype)
+		if err != nil || (mtype != JSONContentType && !strings.HasSuffix(mtype, "+json")) {
+			return &HTTPError{
+				Status:  http.StatusUnsupportedMediaType,
+				Code:    "unsupported_media_type",
+				Message: fmt.Sprintf("unsupported content type '%s'", ctype),
+				Cause:   err,
+			}
+		}
+	}
+
+	err := codec.Decode(r.Body, v)
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return ErrRequestTooLarge.WithCause(err)
+	}
+	if err != nil {
+		return &HTTPError{
+			Status:  http.StatusBadRequest,
+			Code:    "invalid_body",
+			Message: "invalid request body",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+func bindError(f bindField, err error) error {
+	return &HTTPError{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_" + f.tag,
+		Message: fmt.Sprintf("invalid value for %s parameter '%s'", f.tag, f.name),
+		Cause:   err,
+	}
+}
+
+func bindValues(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !field.Addr().Type().Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			err := bindValue(slice.Index(i), value)
+			if err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	return bindValue(field, values[0])
+}
+
+func bindValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return bindValue(field.Elem(), value)
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
@@ -127,6 +127,11 @@ func ErrorSetterHandler(w http.ResponseWriter, r *http.Request) {
 	webgo.R500(w, err.Error())
 }
 
+// ErrorReturnerHandler returns the error, which is then responded by the router's ErrorHandler
+func ErrorReturnerHandler(w http.ResponseWriter, r *http.Request) error {
+	return errors.New("oh no, server error")
+}
+
 func ParamHandler(w http.ResponseWriter, r *http.Request) {
 	// WebGo context
 	wctx := webgo.Context(r)
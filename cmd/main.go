@@ -13,6 +13,9 @@ import (
 	"github.com/bnkamalesh/webgo/v7/extensions/sse"
 	"github.com/bnkamalesh/webgo/v7/middleware/accesslog"
 	"github.com/bnkamalesh/webgo/v7/middleware/cors"
+	"github.com/bnkamalesh/webgo/v7/middleware/recovery"
+	"github.com/bnkamalesh/webgo/v7/middleware/requestid"
+	"github.com/bnkamalesh/webgo/v7/middleware/timeout"
 )
 
 var (
@@ -79,6 +82,13 @@ func getRoutes(sse *sse.SSE) []*webgo.Route {
 			Handlers:      []http.HandlerFunc{ErrorSetterHandler},
 			TrailingSlash: true,
 		},
+		{
+			Name:          "error-returner",
+			Method:        http.MethodGet,
+			Pattern:       "/error-returner",
+			HandlersE:     []webgo.HandlerFuncE{ErrorReturnerHandler},
+			TrailingSlash: true,
+		},
 		{
 			Name:          "original-responsewriter",
 			Method:        http.MethodGet,
@@ -99,6 +109,8 @@ func getRoutes(sse *sse.SSE) []*webgo.Route {
 			Pattern:       "/sse/:clientID",
 			Handlers:      []http.HandlerFunc{SSEHandler(sse)},
 			TrailingSlash: true,
+			// streams are long lived, so the timeout & the server's WriteTimeout are disabled
+			Timeout: -1,
 		},
 	}
 }
@@ -113,7 +125,7 @@ func setup() (*webgo.Router, *sse.SSE) {
 		Port:         port,
 		HTTPSPort:    "9595",
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 1 * time.Hour,
+		WriteTimeout: 60 * time.Second,
 		CertFile:     "./certs/localhost.crt",
 		KeyFile:      "./certs/localhost.decrypted.key",
 	}
@@ -146,9 +158,13 @@ func setup() (*webgo.Router, *sse.SSE) {
 	router := webgo.NewRouter(cfg, routes...)
 	router.UseOnSpecialHandlers(accesslog.AccessLog)
 	router.Use(
+		timeout.Timeout(&timeout.Config{Timeout: 30 * time.Second}),
 		errLogger,
 		cors.CORS(nil),
 		accesslog.AccessLog,
+		requestid.RequestID(nil),
+		// recovery is added last, so that it's executed first
+		recovery.Recovery(nil),
 	)
 
 	return router, sseService
@@ -0,0 +1,122 @@
+package webgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize is the maximum capacity of a buffer which is returned to the pool. Larger
+// buffers are discarded, so that a few large responses do not hold on to memory forever
+const maxPooledBufferSize = 64 * 1024
+
+// codec is the Codec used by all the JSON response helpers & for decoding request body
+var codec Codec = NewJSONCodec()
+
+// Codec is used to encode JSON responses & to decode JSON request body
+type Codec interface {
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+// SetCodec sets the Codec used by SendResponse, SendError, Respond and Bind. A nil codec resets it
+// to the default JSONCodec.
+// IMPORTANT: This is not concurrent safe
+func SetCodec(c Codec) {
+	if c == nil {
+		c = NewJSONCodec()
+	}
+	codec = c
+}
+
+// JSONCodec is the default Codec, which uses encoding/json. The value is completely encoded into
+// a pooled buffer before writing, so nothing is written if there's an encoding error.
+// A JSONCodec should not be copied after first use
+type JSONCodec struct {
+	// EscapeHTML if true, escapes HTML characters (<, >, &) in JSON strings
+	EscapeHTML bool
+	// Indent if not empty, is used to indent the encoded JSON. e.g. "\t" in development mode
+	Indent string
+	// SortKeys if true, sorts the keys of all objects including structs. Keys of maps are always
+	// sorted by encoding/json. This is expensive as the value is encoded twice
+	SortKeys bool
+
+	buffers sync.Pool
+}
+
+// NewJSONCodec returns a JSONCodec with HTML escaping enabled, same as encoding/json
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{
+		EscapeHTML: true,
+	}
+}
+
+func (jc *JSONCodec) getBuffer() *bytes.Buffer {
+	buf, ok := jc.buffers.Get().(*bytes.Buffer)
+	if !ok {
+		return bytes.NewBuffer(make([]byte, 0, 512))
+	}
+	return buf
+}
+
+func (jc *JSONCodec) putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	jc.buffers.Put(buf)
+}
+
+// Encode encodes v as JSON followed by a newline, and writes to w in a single Write
+func (jc *JSONCodec) Encode(w io.Writer, v interface{}) error {
+	buf := jc.getBuffer()
+	defer jc.putBuffer(buf)
+
+	if jc.SortKeys {
+		sorted, err := sortKeys(v)
+		if err != nil {
+			return err
+		}
+		v = sorted
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(jc.EscapeHTML)
+	if jc.Indent != "" {
+		enc.SetIndent("", jc.Indent)
+	}
+
+	err := enc.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// Decode decodes JSON from r into v
+func (jc *JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// sortKeys converts v to a generic value (maps, slices etc.), and since encoding/json sorts the
+// keys of a map, encoding the generic value would have all keys sorted
+func sortKeys(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	// UseNumber retains the precision of the numbers
+	dec.UseNumber()
+
+	var generic interface{}
+	err = dec.Decode(&generic)
+	if err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
@@ -0,0 +1,79 @@
+package webgo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONCodec_Encode(t *testing.T) {
+	t.Parallel()
+	payload := struct {
+		Zeta  string `json:"zeta"`
+		Alpha int64  `json:"alpha"`
+	}{
+		Zeta:  "<b>",
+		Alpha: 9007199254740993,
+	}
+
+	tests := []struct {
+		name  string
+		codec *JSONCodec
+		want  string
+	}{
+		{
+			name:  "default",
+			codec: NewJSONCodec(),
+			want:  `{"zeta":"\u003cb\u003e","alpha":9007199254740993}` + "\n",
+		},
+		{
+			name:  "html escape disabled",
+			codec: &JSONCodec{},
+			want:  `{"zeta":"<b>","alpha":9007199254740993}` + "\n",
+		},
+		{
+			name:  "sorted keys",
+			codec: &JSONCodec{SortKeys: true},
+			want:  `{"alpha":9007199254740993,"zeta":"<b>"}` + "\n",
+		},
+		{
+			name:  "indent",
+			codec: &JSONCodec{Indent: "  "},
+			want:  "{\n  \"zeta\": \"<b>\",\n  \"alpha\": 9007199254740993\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		err := tt.codec.Encode(buf, payload)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.want, buf.String())
+		}
+	}
+
+	// nothing should be written if encoding fails
+	buf := bytes.NewBuffer(nil)
+	err := NewJSONCodec().Encode(buf, []interface{}{"a", make(chan int)})
+	if err == nil {
+		t.Error("expected encoding error, got nil")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, got '%s'", buf.String())
+	}
+}
+
+func TestJSONCodec_Decode(t *testing.T) {
+	t.Parallel()
+	out := map[string]string{}
+	err := NewJSONCodec().Decode(strings.NewReader(`{"hello":"world"}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["hello"] != "world" {
+		t.Errorf("expected 'world', got '%s'", out["hello"])
+	}
+}
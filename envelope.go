@@ -0,0 +1,39 @@
+package webgo
+
+import (
+	"net/http"
+)
+
+// Envelope builds the response body from the payload provided to the response helpers. e.g. to
+// add meta information, request ID, or to rename the keys of the default struct. The request is
+// the one being served by the router
+type Envelope func(r *http.Request, payload interface{}, rCode int) interface{}
+
+// NoEnvelope is an Envelope which responds with the payload as is, without wrapping it
+func NoEnvelope(r *http.Request, payload interface{}, rCode int) interface{} {
+	return payload
+}
+
+// dataEnvelope returns the response body for SendResponse, using the DataEnvelope of the router
+// serving the request. Default is `{data: <payload>, status: <int>}`, with `pagination` if set
+// using Paginate
+func dataEnvelope(crw *customResponseWriter, payload interface{}, rCode int) interface{} {
+	if crw.router != nil && crw.router.DataEnvelope != nil {
+		return crw.router.DataEnvelope(crw.req, payload, rCode)
+	}
+
+	out := dOutput{Data: payload, Status: rCode}
+	if crw.req != nil {
+		out.Pagination = GetPagination(crw.req)
+	}
+	return out
+}
+
+// errorEnvelope returns the response body for SendError, using the ErrorEnvelope of the router
+// serving the request. Default is `{errors: <payload>, status: <int>}`
+func errorEnvelope(crw *customResponseWriter, payload interface{}, rCode int) interface{} {
+	if crw.router != nil && crw.router.ErrorEnvelope != nil {
+		return crw.router.ErrorEnvelope(crw.req, payload, rCode)
+	}
+	return errOutput{payload, rCode}
+}
@@ -0,0 +1,74 @@
+package webgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+	router := NewRouter(&Config{}, &Route{
+		Name:    "envelope",
+		Method:  http.MethodGet,
+		Pattern: "/envelope/:kind",
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				switch Context(r).Params()["kind"] {
+				case "data":
+					R200(w, map[string]string{"hello": "world"})
+				case "error":
+					R400(w, "invalid")
+				case "respond":
+					Respond(w, r, "hello", http.StatusOK)
+				case "encoder":
+					_ = jsonEncoder{}.Encode(w, "hello", http.StatusOK)
+				}
+			},
+		},
+	})
+	router.DataEnvelope = NoEnvelope
+	router.ErrorEnvelope = func(r *http.Request, payload interface{}, rCode int) interface{} {
+		return map[string]interface{}{
+			"error":      payload,
+			"request_id": r.Header.Get("X-Request-ID"),
+		}
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "no envelope",
+			url:  "/envelope/data",
+			want: `{"hello":"world"}` + "\n",
+		},
+		{
+			name: "custom error envelope",
+			url:  "/envelope/error",
+			want: `{"error":"invalid","request_id":"abc"}` + "\n",
+		},
+		{
+			name: "respond",
+			url:  "/envelope/respond",
+			want: `"hello"` + "\n",
+		},
+		{
+			name: "json encoder",
+			url:  "/envelope/encoder",
+			want: `"hello"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		req.Header.Set("X-Request-ID", "abc")
+		router.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.want, w.Body.String())
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"log"
+	"net/http"
 	"os"
 )
 
@@ -71,7 +72,7 @@ func (lh *logHandler) Warn(data ...interface{}) {
 	lh.warn.Println(data...)
 }
 
-//  Error prints log of severity 2
+// Error prints log of severity 2
 func (lh *logHandler) Error(data ...interface{}) {
 	if lh.err == nil {
 		return
@@ -90,6 +91,52 @@ func (lh *logHandler) Fatal(data ...interface{}) {
 // LOGHANDLER is a global variable which webgo uses to log messages
 var LOGHANDLER Logger
 
+// requestLogger prefixes all the logs with the ID of the request
+type requestLogger struct {
+	Logger
+	prefix string
+}
+
+func (rl *requestLogger) withPrefix(data []interface{}) []interface{} {
+	return append([]interface{}{rl.prefix}, data...)
+}
+
+// Debug prints log of severity 5
+func (rl *requestLogger) Debug(data ...interface{}) {
+	rl.Logger.Debug(rl.withPrefix(data)...)
+}
+
+// Info prints logs of severity 4
+func (rl *requestLogger) Info(data ...interface{}) {
+	rl.Logger.Info(rl.withPrefix(data)...)
+}
+
+// Warn prints log of severity 3
+func (rl *requestLogger) Warn(data ...interface{}) {
+	rl.Logger.Warn(rl.withPrefix(data)...)
+}
+
+// Error prints log of severity 2
+func (rl *requestLogger) Error(data ...interface{}) {
+	rl.Logger.Error(rl.withPrefix(data)...)
+}
+
+// Fatal prints log of severity 1
+func (rl *requestLogger) Fatal(data ...interface{}) {
+	rl.Logger.Fatal(rl.withPrefix(data)...)
+}
+
+// RequestLogger returns a Logger which prefixes all the logs of LOGHANDLER with
+// `request_id=<id>`, if the request has an ID (refer SetRequestID). Otherwise it returns LOGHANDLER.
+// Webgo uses it for all the logs while serving a request
+func RequestLogger(r *http.Request) Logger {
+	id := RequestID(r)
+	if id == "" {
+		return LOGHANDLER
+	}
+	return &requestLogger{Logger: LOGHANDLER, prefix: "request_id=" + id}
+}
+
 func init() {
 	GlobalLoggerConfig(nil, nil)
 }
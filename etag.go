@@ -0,0 +1,208 @@
+package webgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderETag is the response header with the entity tag of the response
+	HeaderETag = "ETag"
+	// HeaderLastModified is the response header with the time when the resource was last modified
+	HeaderLastModified = "Last-Modified"
+	// HeaderIfNoneMatch is the request header with the entity tags the client already has
+	HeaderIfNoneMatch = "If-None-Match"
+	// HeaderIfModifiedSince is the request header with the last modified time of the resource the
+	// client already has
+	HeaderIfModifiedSince = "If-Modified-Since"
+
+	// defaultETagMaxSize is the default max size of a response body buffered to compute its ETag
+	defaultETagMaxSize = 1 << 20
+)
+
+// ETagConfig has the configurations for generating ETags of GET & HEAD responses automatically.
+// The response body is buffered to compute its ETag, and the request is responded with 304 Not
+// Modified, if the client already has the response (If-None-Match or If-Modified-Since)
+type ETagConfig struct {
+	// Disabled if true, disables ETags for a route even if they are enabled for the router
+	Disabled bool
+	// MaxSize is the max size of the response body (in bytes) which is buffered. Larger responses
+	// are streamed without an ETag. Default is 1MB
+	MaxSize int64
+	// Weak if true, generates weak ETags (W/"<tag>")
+	Weak bool
+}
+
+// etagConfig returns the ETag configuration applicable for the route, the configuration of the
+// route takes precedence over the router's. It returns nil if ETags are not enabled
+func (rtr *Router) etagConfig(route *Route, method string) *ETagConfig {
+	if method != http.MethodGet && method != http.MethodHead {
+		return nil
+	}
+
+	cfg := rtr.ETag
+	if route.ETag != nil {
+		cfg = route.ETag
+	}
+	if cfg == nil || cfg.Disabled {
+		return nil
+	}
+
+	return cfg
+}
+
+// generateETag returns the ETag of the body, using its length & FNV-1a hash
+func generateETag(body []byte, weak bool) string {
+	hash := fnv.New64a()
+	_, _ = hash.Write(body)
+	tag := fmt.Sprintf(`"%x-%x"`, len(body), hash.Sum64())
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// SetETag sets the ETag response header, tag should not be quoted. If ETags are enabled for the
+// route, this ETag is used instead of the generated one
+func SetETag(w http.ResponseWriter, tag string, weak bool) {
+	etag := `"` + tag + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+	w.Header().Set(HeaderETag, etag)
+}
+
+// SetLastModified sets the Last-Modified response header
+func SetLastModified(w http.ResponseWriter, modtime time.Time) {
+	w.Header().Set(HeaderLastModified, modtime.UTC().Format(http.TimeFormat))
+}
+
+// scanETags returns all the entity tags in the header value, e.g. `"a", W/"b"`
+func scanETags(value string) []string {
+	tags := make([]string, 0, 1)
+	for {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			return tags
+		}
+
+		if value[0] == '*' {
+			tags = append(tags, "*")
+			value = value[1:]
+			continue
+		}
+
+		start := 0
+		if strings.HasPrefix(value, "W/") {
+			start = 2
+		}
+		if len(value) <= start || value[start] != '"' {
+			// invalid entity tag, rest of the value is ignored
+			return tags
+		}
+
+		end := strings.IndexByte(value[start+1:], '"')
+		if end < 0 {
+			return tags
+		}
+		end += start + 2
+		tags = append(tags, value[:end])
+		value = value[end:]
+	}
+}
+
+// etagMatch returns true if etag matches any of the entity tags in the header value. Weak
+// comparison ignores the weak indicator, whereas with strong comparison both the tags should be strong
+func etagMatch(value string, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, tag := range scanETags(value) {
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+		if tag == etag && !strings.HasPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotModified returns true if the client already has the response with the given response header
+func isNotModified(r *http.Request, header http.Header) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-Modified-Since is ignored if the request has If-None-Match, RFC 9110, 13.1.3
+	if inm := r.Header.Get(HeaderIfNoneMatch); inm != "" {
+		return etagMatch(inm, header.Get(HeaderETag), true)
+	}
+
+	ims, err := http.ParseTime(r.Header.Get(HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	modtime, err := http.ParseTime(header.Get(HeaderLastModified))
+	if err != nil {
+		return false
+	}
+	return !modtime.Truncate(time.Second).After(ims)
+}
+
+// notModifiedHeader removes the headers which are not applicable for a 304 response
+func notModifiedHeader(header http.Header) {
+	header.Del(HeaderContentType)
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+	if header.Get(HeaderETag) != "" {
+		header.Del(HeaderLastModified)
+	}
+}
+
+// NotModified responds with 304 Not Modified and returns true, if the client already has the
+// response. The validators should be set using SetETag or SetLastModified before calling it, so
+// that handlers can skip building a response the client already has. e.g.
+//
+//	webgo.SetETag(w, user.Version, false)
+//	if webgo.NotModified(w, r) {
+//		return
+//	}
+func NotModified(w http.ResponseWriter, r *http.Request) bool {
+	if !isNotModified(r, w.Header()) {
+		return false
+	}
+
+	notModifiedHeader(w.Header())
+	SendHeader(w, http.StatusNotModified)
+	return true
+}
+
+// setETag sets the ETag of the buffered response, and replaces it with 304 if the client already
+// has it. Responses which were too large to be buffered are already streamed to the client
+func setETag(crw *customResponseWriter, r *http.Request, cfg *ETagConfig) {
+	if !crw.buffered || !crw.headerWritten || crw.statusCode != http.StatusOK {
+		return
+	}
+
+	header := crw.Header()
+	if header.Get(HeaderETag) == "" {
+		header.Set(HeaderETag, generateETag(crw.buffer.Bytes(), cfg.Weak))
+	}
+
+	if isNotModified(r, header) {
+		notModifiedHeader(header)
+		crw.statusCode = http.StatusNotModified
+		crw.buffer.Reset()
+	}
+}
@@ -0,0 +1,152 @@
+package webgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	t.Parallel()
+	modtime := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	router := NewRouter(&Config{},
+		&Route{
+			Name:    "generated",
+			Method:  http.MethodGet,
+			Pattern: "/generated",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					SendResponse(w, "hello", http.StatusOK)
+				},
+			},
+		},
+		&Route{
+			Name:    "custom",
+			Method:  http.MethodGet,
+			Pattern: "/custom",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					SetETag(w, "v1", false)
+					SetLastModified(w, modtime)
+					SendResponse(w, "hello", http.StatusOK)
+				},
+			},
+		},
+		&Route{
+			Name:    "large",
+			Method:  http.MethodGet,
+			Pattern: "/large",
+			ETag:    &ETagConfig{MaxSize: 10},
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					SendResponse(w, strings.Repeat("a", 100), http.StatusOK)
+				},
+			},
+		},
+		&Route{
+			Name:    "disabled",
+			Method:  http.MethodGet,
+			Pattern: "/disabled",
+			ETag:    &ETagConfig{Disabled: true},
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					SendResponse(w, "hello", http.StatusOK)
+				},
+			},
+		},
+		&Route{
+			Name:    "error",
+			Method:  http.MethodGet,
+			Pattern: "/error",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					R404(w, "not found")
+				},
+			},
+		},
+	)
+	router.ETag = &ETagConfig{}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/generated", nil)
+	router.ServeHTTP(w, req)
+	etag := w.Header().Get(HeaderETag)
+	if w.Code != http.StatusOK || etag == "" || etag != generateETag(w.Body.Bytes(), false) {
+		t.Fatalf("expected status 200 with the ETag of the body, got '%d', '%s'", w.Code, etag)
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		header     map[string]string
+		wantStatus int
+		wantETag   bool
+		wantBody   bool
+	}{
+		{name: "no match", url: "/generated", header: map[string]string{HeaderIfNoneMatch: `"abc"`}, wantStatus: http.StatusOK, wantETag: true, wantBody: true},
+		{name: "match", url: "/generated", header: map[string]string{HeaderIfNoneMatch: `"abc", ` + etag}, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "weak match", url: "/generated", header: map[string]string{HeaderIfNoneMatch: "W/" + etag}, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "wildcard", url: "/generated", header: map[string]string{HeaderIfNoneMatch: "*"}, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "custom etag", url: "/custom", header: map[string]string{HeaderIfNoneMatch: `"v1"`}, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "modified since", url: "/custom", header: map[string]string{HeaderIfModifiedSince: modtime.Add(-time.Hour).Format(http.TimeFormat)}, wantStatus: http.StatusOK, wantETag: true, wantBody: true},
+		{name: "not modified since", url: "/custom", header: map[string]string{HeaderIfModifiedSince: modtime.Format(http.TimeFormat)}, wantStatus: http.StatusNotModified, wantETag: true},
+		{name: "too large", url: "/large", header: map[string]string{HeaderIfNoneMatch: "*"}, wantStatus: http.StatusOK, wantBody: true},
+		{name: "disabled", url: "/disabled", header: map[string]string{HeaderIfNoneMatch: "*"}, wantStatus: http.StatusOK, wantBody: true},
+		{name: "error", url: "/error", header: map[string]string{HeaderIfNoneMatch: "*"}, wantStatus: http.StatusNotFound, wantBody: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d'", tt.wantStatus, w.Code)
+			}
+			if gotETag := w.Header().Get(HeaderETag) != ""; gotETag != tt.wantETag {
+				t.Errorf("expected ETag '%t', got '%s'", tt.wantETag, w.Header().Get(HeaderETag))
+			}
+			if gotBody := w.Body.Len() > 0; gotBody != tt.wantBody {
+				t.Errorf("expected body '%t', got '%s'", tt.wantBody, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusNotModified && w.Header().Get(HeaderContentType) != "" {
+				t.Errorf("expected no content type for 304, got '%s'", w.Header().Get(HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderIfNoneMatch, `W/"v2"`)
+
+	SetETag(w, "v1", true)
+	if NotModified(w, req) {
+		t.Fatal("expected the response to be modified")
+	}
+
+	SetETag(w, "v2", true)
+	if !NotModified(w, req) {
+		t.Fatal("expected the response to be not modified")
+	}
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status '%d', got '%d'", http.StatusNotModified, w.Code)
+	}
+}
+
+func TestScanETags(t *testing.T) {
+	t.Parallel()
+	got := scanETags(` "a", W/"b,c" ,*, invalid`)
+	want := []string{`"a"`, `W/"b,c"`, "*"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
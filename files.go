@@ -0,0 +1,115 @@
+package webgo
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// HeaderContentDisposition is the response header which tells the client whether to
+	// display the content inline, or to download it as a file
+	HeaderContentDisposition = "Content-Disposition"
+
+	// DispositionInline lets the client display the content inline
+	DispositionInline = "inline"
+	// DispositionAttachment makes the client download the content as a file
+	DispositionAttachment = "attachment"
+)
+
+// FileOptions are the options available for SendFile & Attachment
+type FileOptions struct {
+	// ContentType of the file. If empty, it is detected from the extension of the file name,
+	// or by sniffing the content
+	ContentType string
+	// Disposition is the type of Content-Disposition, DispositionInline or DispositionAttachment.
+	// Default is DispositionInline
+	Disposition string
+	// ETag is the entity tag of the file, used for conditional & range requests
+	ETag string
+}
+
+// isAttrChar returns true if c is an attr-char as per RFC 5987, which need not be percent encoded
+func isAttrChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// ContentDisposition returns the RFC 6266 Content-Disposition header value. The filename is
+// provided as an ASCII only fallback, and as UTF-8 encoded `filename*` if it has any other characters
+func ContentDisposition(disposition string, filename string) string {
+	if filename == "" {
+		return disposition
+	}
+
+	fallback := strings.Builder{}
+	for _, c := range filename {
+		switch {
+		case c >= utf8.RuneSelf, c < 0x20, c == 0x7f, c == '"', c == '\\':
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(c)
+		}
+	}
+
+	encoded := strings.Builder{}
+	for i := 0; i < len(filename); i++ {
+		c := filename[i]
+		if isAttrChar(c) {
+			encoded.WriteByte(c)
+			continue
+		}
+		encoded.WriteByte('%')
+		encoded.WriteByte("0123456789ABCDEF"[c>>4])
+		encoded.WriteByte("0123456789ABCDEF"[c&15])
+	}
+
+	value := disposition + `; filename="` + fallback.String() + `"`
+	if fallback.String() != filename {
+		value += "; filename*=UTF-8''" + encoded.String()
+	}
+	return value
+}
+
+// SendFile responds with the content, using http.ServeContent. So it supports single & multi-range
+// requests, If-Range, conditional requests based on modtime & ETag, and content type detection.
+// The Content-Disposition header is set with name as the filename, unless name is empty
+func SendFile(w http.ResponseWriter, r *http.Request, name string, content io.ReadSeeker, modtime time.Time, opts *FileOptions) {
+	if opts == nil {
+		opts = &FileOptions{}
+	}
+
+	disposition := opts.Disposition
+	if disposition == "" {
+		disposition = DispositionInline
+	}
+
+	crw := crwAsserter(w, http.StatusOK)
+	header := crw.Header()
+	if opts.ContentType != "" {
+		header.Set(HeaderContentType, opts.ContentType)
+	}
+	if opts.ETag != "" {
+		header.Set("ETag", opts.ETag)
+	}
+	if name != "" {
+		header.Set(HeaderContentDisposition, ContentDisposition(disposition, name))
+	}
+
+	http.ServeContent(crw, r, name, modtime, content)
+}
+
+// Attachment is same as SendFile, except the client is asked to download the content as a file
+func Attachment(w http.ResponseWriter, r *http.Request, name string, content io.ReadSeeker, modtime time.Time, opts *FileOptions) {
+	o := FileOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Disposition = DispositionAttachment
+	SendFile(w, r, name, content, modtime, &o)
+}
@@ -0,0 +1,109 @@
+package webgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentDisposition(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		disposition string
+		filename    string
+		want        string
+	}{
+		{DispositionAttachment, "", "attachment"},
+		{DispositionAttachment, "report.csv", `attachment; filename="report.csv"`},
+		{DispositionInline, `a "b".txt`, `inline; filename="a _b_.txt"; filename*=UTF-8''a%20%22b%22.txt`},
+		{DispositionAttachment, "résumé.pdf", `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`},
+	}
+	for _, tt := range tests {
+		got := ContentDisposition(tt.disposition, tt.filename)
+		if got != tt.want {
+			t.Errorf("expected '%s', got '%s'", tt.want, got)
+		}
+	}
+}
+
+func TestSendFile(t *testing.T) {
+	t.Parallel()
+	content := "hello world, this is a file"
+	modtime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := 0
+	router := NewRouter(&Config{}, &Route{
+		Name:    "file",
+		Method:  http.MethodGet,
+		Pattern: "/file",
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				Attachment(w, r, "notes.txt", strings.NewReader(content), modtime, &FileOptions{ETag: `"v1"`})
+				status = ResponseStatus(w)
+			},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "full",
+			wantStatus: http.StatusOK,
+			wantBody:   content,
+		},
+		{
+			name:       "single range",
+			headers:    map[string]string{"Range": "bytes=0-4"},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "hello",
+		},
+		{
+			name:       "multi range",
+			headers:    map[string]string{"Range": "bytes=0-4,6-10"},
+			wantStatus: http.StatusPartialContent,
+		},
+		{
+			name:       "if-range mismatch",
+			headers:    map[string]string{"Range": "bytes=0-4", "If-Range": `"v0"`},
+			wantStatus: http.StatusOK,
+			wantBody:   content,
+		},
+		{
+			name:       "not modified",
+			headers:    map[string]string{"If-None-Match": `"v1"`},
+			wantStatus: http.StatusNotModified,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/file", nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus || status != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d (ResponseStatus %d)", tt.name, tt.wantStatus, w.Code, status)
+		}
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Errorf("%s: expected body '%s', got '%s'", tt.name, tt.wantBody, w.Body.String())
+		}
+		if got := w.Header().Get(HeaderContentDisposition); got != `attachment; filename="notes.txt"` {
+			t.Errorf("%s: unexpected Content-Disposition '%s'", tt.name, got)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/file", nil)
+	req.Header.Set("Range", "bytes=0-4,6-10")
+	router.ServeHTTP(w, req)
+	if !strings.HasPrefix(w.Header().Get(HeaderContentType), "multipart/byteranges") {
+		t.Errorf("expected multipart response, got '%s'", w.Header().Get(HeaderContentType))
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/bnkamalesh/webgo/v7
 
-go 1.19
+go 1.20
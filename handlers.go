@@ -0,0 +1,62 @@
+package webgo
+
+import (
+	"net/http"
+)
+
+// HandlerFuncE is an HTTP handler which returns an error instead of responding to the client on
+// failure. The returned error is set in the webgo context and is responded using the router's
+// ErrorHandler
+type HandlerFuncE func(http.ResponseWriter, *http.Request) error
+
+// ServeHTTP implements the http.Handler interface. If the handler has already responded to the
+// client, the error is only set in the webgo context & logged, unless the response is buffered
+// (refer DiscardResponse)
+func (hfe HandlerFuncE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := hfe(w, r)
+	if err == nil {
+		return
+	}
+
+	rd, _ := ResponseInfo(w)
+	if rd.HeaderWritten && !DiscardResponse(w) {
+		SetError(r, err)
+		RequestLogger(r).Error(err)
+		return
+	}
+	HandleError(w, r, err)
+}
+
+// ErrorHandler is the signature of the function used to convert an error, returned by a
+// HandlerFuncE, to an HTTP response
+type ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+// DefaultErrorHandler is the ErrorHandler used by the router if none is provided. The error
+// is converted to an HTTPError (refer AsHTTPError), and is responded using SendError.
+// Errors which are not HTTPErrors are never exposed to the client
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	herr := AsHTTPError(r, err)
+	SendError(w, herr, herr.Status)
+}
+
+// HandleError sets the error in the webgo context and responds using the ErrorHandler of the router
+// serving the request. DefaultErrorHandler is used if the request is not being served by a router.
+// It lets middleware respond with errors the same way as the handlers
+func HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	SetError(r, err)
+	RespondError(w, r, err)
+}
+
+// RespondError responds using the ErrorHandler of the router serving the request, like HandleError,
+// except that the error is not set in the webgo context. e.g. to respond from a middleware while
+// the handlers are still being executed in another goroutine
+func RespondError(w http.ResponseWriter, r *http.Request, err error) {
+	eh := ErrorHandler(DefaultErrorHandler)
+
+	cp := webgoContext(r)
+	if cp != nil && cp.router != nil && cp.router.ErrorHandler != nil {
+		eh = cp.router.ErrorHandler
+	}
+
+	eh(w, r, err)
+}
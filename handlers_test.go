@@ -0,0 +1,135 @@
+package webgo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerFuncE(t *testing.T) {
+	t.Parallel()
+	herr := errors.New("handler failed")
+	handled := false
+	router := NewRouter(&Config{}, &Route{
+		Name:    "errhandler",
+		Method:  http.MethodGet,
+		Pattern: "/err",
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("chained", "true")
+			},
+		},
+		HandlersE: []HandlerFuncE{
+			func(w http.ResponseWriter, r *http.Request) error {
+				return herr
+			},
+		},
+	})
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = true
+		if !errors.Is(GetError(r), herr) {
+			t.Errorf("expected error '%v' in context, got '%v'", herr, GetError(r))
+		}
+		SendError(w, err.Error(), http.StatusTeapot)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/err", nil)
+	router.ServeHTTP(w, req)
+
+	if !handled {
+		t.Error("expected error handler to be called")
+	}
+	if w.Code != http.StatusTeapot {
+		t.Errorf("expected status %d, got %d", http.StatusTeapot, w.Code)
+	}
+	if w.Header().Get("chained") != "true" {
+		t.Error("expected handlers to be executed before HandlersE")
+	}
+
+	// without a router, the default error handler should be used
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/err", nil)
+	HandlerFuncE(func(w http.ResponseWriter, r *http.Request) error {
+		return herr
+	}).ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestHandlersESharedSlice(t *testing.T) {
+	t.Parallel()
+	handlers := make([]http.HandlerFunc, 1, 2)
+	handlers[0] = func(w http.ResponseWriter, r *http.Request) {}
+
+	route := func(name string, status int) *Route {
+		return &Route{
+			Name:     name,
+			Method:   http.MethodGet,
+			Pattern:  "/" + name,
+			Handlers: handlers,
+			HandlersE: []HandlerFuncE{func(w http.ResponseWriter, r *http.Request) error {
+				return NewHTTPError(status, name, "")
+			}},
+		}
+	}
+	router := NewRouter(&Config{}, route("first", http.StatusBadRequest), route("second", http.StatusConflict))
+
+	for path, status := range map[string]int{"/first": http.StatusBadRequest, "/second": http.StatusConflict} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != status {
+			t.Errorf("expected status %d for '%s', got %d", status, path, w.Code)
+		}
+	}
+	if len(handlers) != 1 {
+		t.Errorf("expected the Handlers of the routes to be unchanged, got %d handlers", len(handlers))
+	}
+}
+
+func TestHandlerFuncEPartialResponse(t *testing.T) {
+	t.Parallel()
+	herr := errors.New("handler failed")
+	partial := HandlerFuncE(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("partial"))
+		return herr
+	})
+	router := NewRouter(&Config{}, &Route{
+		Name:      "partial",
+		Method:    http.MethodGet,
+		Pattern:   "/partial",
+		HandlersE: []HandlerFuncE{partial},
+	}, &Route{
+		Name:       "buffered",
+		Method:     http.MethodGet,
+		Pattern:    "/buffered",
+		BufferSize: 1024,
+		HandlersE:  []HandlerFuncE{partial},
+	})
+
+	var ctxErr error
+	router.Use(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(w, r)
+		ctxErr = GetError(r)
+	})
+	router.SetupMiddleware()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/partial", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("expected the partial response as is, got '%d': %s", w.Code, w.Body.String())
+	}
+	if !errors.Is(ctxErr, herr) {
+		t.Errorf("expected error '%v' in context, got '%v'", herr, ctxErr)
+	}
+
+	// a buffered response is replaced with the error
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/buffered", nil))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "partial") {
+		t.Errorf("expected the error response, got '%d': %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,144 @@
+package webgo
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrRequestTooLarge is responded when the request body is larger than the limit, e.g. set using
+// http.MaxBytesReader. Any error wrapping *http.MaxBytesError is converted to it
+var ErrRequestTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "request_too_large", "")
+
+// HTTPError is an error which carries all the information required to respond to the client.
+// Any error returned by a HandlerFuncE or set using SetError is unwrapped to find an HTTPError,
+// and its status is used as the HTTP response status code
+type HTTPError struct {
+	// Status is the HTTP response status code
+	Status int `json:"-"`
+	// Code is an application specific error code
+	Code string `json:"code,omitempty"`
+	// Message is the human readable error message, which is responded to the client
+	Message string `json:"message,omitempty"`
+	// Details can be any additional information about the error. e.g. validation errors
+	Details interface{} `json:"details,omitempty"`
+	// Cause is the underlying error, it is never responded to the client
+	Cause error `json:"-"`
+}
+
+// NewHTTPError returns a new HTTPError with the status code & message provided. If message
+// is empty, the status text of the status code is used
+func NewHTTPError(status int, code string, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &HTTPError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (he *HTTPError) Error() string {
+	msg := he.Message
+	if msg == "" {
+		msg = http.StatusText(he.Status)
+	}
+
+	if he.Cause != nil {
+		return msg + ": " + he.Cause.Error()
+	}
+
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (he *HTTPError) Unwrap() error {
+	return he.Cause
+}
+
+// Is reports whether target is an HTTPError with the same status & code. It lets sentinel
+// HTTPErrors be compared using errors.Is, even after a cause is attached using WithCause
+func (he *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	if !ok {
+		return false
+	}
+	return t.Status == he.Status && t.Code == he.Code
+}
+
+// WithCause returns a copy of the HTTPError with cause set as the underlying error
+func (he *HTTPError) WithCause(cause error) *HTTPError {
+	cpy := *he
+	cpy.Cause = cause
+	return &cpy
+}
+
+// WithDetails returns a copy of the HTTPError with details set
+func (he *HTTPError) WithDetails(details interface{}) *HTTPError {
+	cpy := *he
+	cpy.Details = details
+	return &cpy
+}
+
+// errMapping maps a domain error to an HTTPError
+type errMapping struct {
+	target error
+	herr   *HTTPError
+}
+
+// MapError registers target (e.g. sql.ErrNoRows) to be responded as herr. Any error matching
+// target, using errors.Is, would use the status, code & message of herr.
+// IMPORTANT: This is not concurrent safe, and should be used only while setting up the router
+func (rtr *Router) MapError(target error, herr *HTTPError) {
+	rtr.errMappings = append(rtr.errMappings, errMapping{target: target, herr: herr})
+}
+
+// HTTPError converts err to an HTTPError. If err wraps an HTTPError, it is returned as is,
+// else the errors registered using MapError are checked in the order they were added. Errors of
+// reading a body larger than its limit are converted to ErrRequestTooLarge. Any other error is
+// converted to an internal server error
+func (rtr *Router) HTTPError(err error) *HTTPError {
+	return toHTTPError(err, rtr.errMappings)
+}
+
+func toHTTPError(err error, mappings []errMapping) *HTTPError {
+	if err == nil {
+		return nil
+	}
+
+	var herr *HTTPError
+	if errors.As(err, &herr) {
+		if herr.Status != 0 {
+			return herr
+		}
+		cpy := *herr
+		cpy.Status = http.StatusInternalServerError
+		return &cpy
+	}
+
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return ErrRequestTooLarge.WithCause(err)
+	}
+
+	for _, em := range mappings {
+		if errors.Is(err, em.target) {
+			return em.herr.WithCause(err)
+		}
+	}
+
+	return &HTTPError{
+		Status:  http.StatusInternalServerError,
+		Message: ErrInternalServer,
+		Cause:   err,
+	}
+}
+
+// AsHTTPError converts err to an HTTPError using the router serving the request. Refer
+// Router.HTTPError for the conversion rules
+func AsHTTPError(r *http.Request, err error) *HTTPError {
+	if cp := webgoContext(r); cp != nil && cp.router != nil {
+		return cp.router.HTTPError(err)
+	}
+	return toHTTPError(err, nil)
+}
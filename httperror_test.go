@@ -0,0 +1,142 @@
+package webgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPError(t *testing.T) {
+	t.Parallel()
+	errNotFound := NewHTTPError(http.StatusNotFound, "not_found", "")
+	cause := errors.New("no rows")
+	err := fmt.Errorf("wrapped: %w", errNotFound.WithCause(cause))
+
+	if !errors.Is(err, errNotFound) {
+		t.Error("expected error to match the sentinel HTTPError")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected error to match the cause")
+	}
+
+	var herr *HTTPError
+	if !errors.As(err, &herr) {
+		t.Fatal("expected errors.As to find HTTPError")
+	}
+	if herr.Status != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, herr.Status)
+	}
+	if herr.Message != http.StatusText(http.StatusNotFound) {
+		t.Errorf("expected message '%s', got '%s'", http.StatusText(http.StatusNotFound), herr.Message)
+	}
+}
+
+func TestRouter_HTTPError(t *testing.T) {
+	t.Parallel()
+	errNoRows := errors.New("no rows in result set")
+	rtr := NewRouter(&Config{})
+	rtr.MapError(errNoRows, NewHTTPError(http.StatusNotFound, "not_found", "user not found"))
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "http error",
+			err:        fmt.Errorf("failed: %w", &HTTPError{Status: http.StatusConflict, Code: "conflict"}),
+			wantStatus: http.StatusConflict,
+			wantCode:   "conflict",
+		},
+		{
+			name:       "http error without status",
+			err:        &HTTPError{Code: "unknown"},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "unknown",
+		},
+		{
+			name:       "mapped error",
+			err:        fmt.Errorf("get user: %w", errNoRows),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not_found",
+		},
+		{
+			name:       "body too large",
+			err:        fmt.Errorf("read body: %w", &http.MaxBytesError{Limit: 10}),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   "request_too_large",
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("unknown"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		herr := rtr.HTTPError(tt.err)
+		if herr.Status != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, herr.Status)
+		}
+		if herr.Code != tt.wantCode {
+			t.Errorf("%s: expected code '%s', got '%s'", tt.name, tt.wantCode, herr.Code)
+		}
+	}
+}
+
+func TestSetErrorWithoutResponse(t *testing.T) {
+	t.Parallel()
+	errNoRows := errors.New("no rows in result set")
+	router := NewRouter(&Config{}, &Route{
+		Name:    "seterror",
+		Method:  http.MethodGet,
+		Pattern: "/seterror",
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				SetError(r, fmt.Errorf("get user: %w", errNoRows))
+			},
+		},
+	})
+	router.MapError(errNoRows, NewHTTPError(http.StatusNotFound, "not_found", "user not found"))
+
+	// the middleware should see the status of the error response, even if the response writer
+	// is wrapped by another middleware
+	middlewareStatus := 0
+	router.Use(
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(w, r)
+			middlewareStatus = ResponseStatus(w)
+		},
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(&wrapperWriter{ResponseWriter: w}, r)
+		},
+	)
+	router.SetupMiddleware()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/seterror", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if middlewareStatus != http.StatusNotFound {
+		t.Errorf("expected status %d in the middleware, got %d", http.StatusNotFound, middlewareStatus)
+	}
+
+	resp := struct {
+		Errors HTTPError
+		Status int
+	}{}
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Errors.Code != "not_found" || resp.Errors.Message != "user not found" {
+		t.Errorf("unexpected error response '%s'", w.Body.String())
+	}
+	if resp.Status != http.StatusNotFound {
+		t.Errorf("expected status %d in body, got %d", http.StatusNotFound, resp.Status)
+	}
+}
@@ -2,6 +2,8 @@
 Package accesslogs provides a simple straight forward access log middleware. The logs are of the
 following format:
 <timestamp> <HTTP request method> <full URL including query string parameters> <duration of execution> <HTTP response status code>
+
+If the request has an ID (refer the requestid middleware), the logs are prefixed with `request_id=<id>`.
 */
 package accesslog
 
@@ -19,7 +21,7 @@ func AccessLog(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc)
 	next(rw, req)
 	end := time.Now()
 
-	webgo.LOGHANDLER.Info(
+	webgo.RequestLogger(req).Info(
 		fmt.Sprintf(
 			"%s %s %s %d",
 			req.Method,
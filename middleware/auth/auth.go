@@ -0,0 +1,227 @@
+/*
+Package auth provides middleware to authenticate requests using Basic auth, Bearer tokens & JWTs.
+The identity of an authenticated request is available using GetIdentity, and the verified claims
+of a JWT using GetClaims. Requests which fail authentication are responded with 401 &
+WWW-Authenticate, using the router's ErrorHandler.
+*/
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	// HeaderWWWAuthenticate is the response header with the authentication challenge
+	HeaderWWWAuthenticate = "WWW-Authenticate"
+	// HeaderAuthorization is the request header with the credentials
+	HeaderAuthorization = "Authorization"
+
+	// SchemeBasic is the Basic authentication scheme
+	SchemeBasic = "Basic"
+	// SchemeBearer is the Bearer authentication scheme
+	SchemeBearer = "Bearer"
+
+	defaultRealm = "Restricted"
+)
+
+type ctxkey string
+
+const identityKey = ctxkey("identity")
+
+var (
+	// ErrUnauthorized is responded when a request fails authentication
+	ErrUnauthorized = webgo.NewHTTPError(http.StatusUnauthorized, "unauthorized", "")
+	// ErrMissingCredentials is the cause of ErrUnauthorized, when the request has no credentials
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials is the cause of ErrUnauthorized, when the credentials are invalid
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity is the identity of an authenticated request
+type Identity struct {
+	// Scheme is the authentication scheme, e.g. Basic, Bearer
+	Scheme string
+	// Subject is the username, or the subject of the token
+	Subject string
+	// Claims are the verified claims, if the request was authenticated using a JWT
+	Claims *Claims
+	// Value is any additional information about the identity, e.g. a user returned by the
+	// validator of Bearer tokens
+	Value interface{}
+}
+
+// GetIdentity returns the identity of the request, if it's authenticated
+func GetIdentity(r *http.Request) (*Identity, bool) {
+	id, ok := r.Context().Value(identityKey).(*Identity)
+	return id, ok && id != nil
+}
+
+// GetClaims returns the verified claims of the request, if it's authenticated using a JWT
+func GetClaims(r *http.Request) (*Claims, bool) {
+	id, ok := GetIdentity(r)
+	if !ok || id.Claims == nil {
+		return nil, false
+	}
+	return id.Claims, true
+}
+
+func withIdentity(r *http.Request, id *Identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityKey, id))
+}
+
+// credentials returns the credentials of the Authorization header, if it has the scheme
+func credentials(r *http.Request, scheme string) (string, bool) {
+	value := strings.TrimSpace(r.Header.Get(HeaderAuthorization))
+	if len(value) <= len(scheme) || !strings.EqualFold(value[:len(scheme)], scheme) || value[len(scheme)] != ' ' {
+		return "", false
+	}
+	creds := strings.TrimSpace(value[len(scheme):])
+	return creds, creds != ""
+}
+
+// unauthorized responds with 401 & the challenge. The HTTPError returned by a validator is
+// responded as is, the challenge is set only if its status is 401
+func unauthorized(w http.ResponseWriter, r *http.Request, challenge string, err error) {
+	herr := &webgo.HTTPError{}
+	if !errors.As(err, &herr) {
+		herr = ErrUnauthorized.WithCause(err)
+	}
+	if herr.Status == http.StatusUnauthorized {
+		w.Header().Set(HeaderWWWAuthenticate, challenge)
+	}
+	webgo.HandleError(w, r, herr)
+}
+
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// LookupFunc returns the password of the user, found is false if the user does not exist
+type LookupFunc func(r *http.Request, username string) (password string, found bool, err error)
+
+// Users returns a LookupFunc for a static list of users, with the username as the key & the
+// password as the value
+func Users(users map[string]string) LookupFunc {
+	return func(r *http.Request, username string) (string, bool, error) {
+		password, ok := users[username]
+		return password, ok, nil
+	}
+}
+
+// BasicConfig has the configurations of the Basic auth middleware
+type BasicConfig struct {
+	// Realm is the realm of the challenge, default is "Restricted"
+	Realm string
+	// Lookup returns the password of the user
+	Lookup LookupFunc
+}
+
+// passwordMatch compares the passwords in constant time. The hashes are compared, so that the
+// duration does not depend on the length of the passwords either
+func passwordMatch(given, expected string) bool {
+	g, e := sha256.Sum256([]byte(given)), sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(g[:], e[:]) == 1
+}
+
+// Basic returns the Basic auth middleware. The passwords are compared in constant time, including
+// for users which do not exist. If Lookup fails, the error is responded using the ErrorHandler
+func Basic(cfg *BasicConfig) webgo.Middleware {
+	c := BasicConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Realm == "" {
+		c.Realm = defaultRealm
+	}
+	if c.Lookup == nil {
+		c.Lookup = Users(nil)
+	}
+	challenge := fmt.Sprintf(`%s realm=%s, charset="UTF-8"`, SchemeBasic, quote(c.Realm))
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		username, password, ok := req.BasicAuth()
+		if !ok {
+			unauthorized(rw, req, challenge, ErrMissingCredentials)
+			return
+		}
+
+		expected, found, err := c.Lookup(req, username)
+		if err != nil {
+			webgo.HandleError(rw, req, err)
+			return
+		}
+		// the password is compared even if the user is not found, so that the response time
+		// does not reveal if the user exists
+		match := passwordMatch(password, expected)
+		if !found || !match {
+			unauthorized(rw, req, challenge, ErrInvalidCredentials)
+			return
+		}
+
+		next(rw, withIdentity(req, &Identity{Scheme: SchemeBasic, Subject: username}))
+	}
+}
+
+// ValidatorFunc validates a Bearer token, and returns the identity of the token. Any error is
+// responded with 401, unless it's an HTTPError, e.g. 403 for a token without the required scope
+type ValidatorFunc func(r *http.Request, token string) (*Identity, error)
+
+// BearerConfig has the configurations of the Bearer auth middleware
+type BearerConfig struct {
+	// Realm is the realm of the challenge, default is "Restricted"
+	Realm string
+	// Validate validates the token
+	Validate ValidatorFunc
+}
+
+// Bearer returns the Bearer token auth middleware, the challenge on failure is as per RFC 6750
+func Bearer(cfg *BearerConfig) webgo.Middleware {
+	c := BearerConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Realm == "" {
+		c.Realm = defaultRealm
+	}
+	if c.Validate == nil {
+		c.Validate = func(r *http.Request, token string) (*Identity, error) {
+			return nil, ErrInvalidCredentials
+		}
+	}
+	challenge := fmt.Sprintf(`%s realm=%s`, SchemeBearer, quote(c.Realm))
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		token, ok := credentials(req, SchemeBearer)
+		if !ok {
+			unauthorized(rw, req, challenge, ErrMissingCredentials)
+			return
+		}
+
+		id, err := c.Validate(req, token)
+		if err == nil && id == nil {
+			err = ErrInvalidCredentials
+		}
+		if err != nil {
+			unauthorized(rw, req, challenge+`, error="invalid_token"`, err)
+			return
+		}
+		if id.Scheme == "" {
+			id.Scheme = SchemeBearer
+		}
+
+		next(rw, withIdentity(req, id))
+	}
+}
+
+// JWT returns the Bearer token auth middleware, which verifies the tokens using the verifier
+func JWT(v *Verifier, realm string) webgo.Middleware {
+	return Bearer(&BearerConfig{Realm: realm, Validate: v.Validate})
+}
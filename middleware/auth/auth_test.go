@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+func newRouter(mw webgo.Middleware) *webgo.Router {
+	router := webgo.NewRouter(&webgo.Config{},
+		&webgo.Route{
+			Name:    "private",
+			Method:  http.MethodGet,
+			Pattern: "/private",
+			Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				id, ok := GetIdentity(r)
+				if !ok {
+					webgo.R500(w, "no identity")
+					return
+				}
+				webgo.R200(w, id.Scheme+":"+id.Subject)
+			}},
+		},
+	)
+	router.Use(mw)
+	router.SetupMiddleware()
+	return router
+}
+
+func TestBasic(t *testing.T) {
+	router := newRouter(Basic(&BasicConfig{
+		Realm: "admin",
+		Lookup: func(r *http.Request, username string) (string, bool, error) {
+			if username == "broken" {
+				return "", false, errors.New("lookup failed")
+			}
+			return Users(map[string]string{"alice": "secret"})(r, username)
+		},
+	}))
+
+	tests := []struct {
+		name          string
+		username      string
+		password      string
+		noAuth        bool
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+	}{
+		{name: "valid", username: "alice", password: "secret", wantStatus: http.StatusOK, wantBody: "Basic:alice"},
+		{name: "wrong password", username: "alice", password: "secre", wantStatus: http.StatusUnauthorized, wantChallenge: `Basic realm="admin", charset="UTF-8"`},
+		{name: "unknown user", username: "bob", password: "secret", wantStatus: http.StatusUnauthorized, wantChallenge: `Basic realm="admin", charset="UTF-8"`},
+		{name: "missing", noAuth: true, wantStatus: http.StatusUnauthorized, wantChallenge: `Basic realm="admin", charset="UTF-8"`},
+		{name: "lookup error", username: "broken", password: "x", wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/private", nil)
+			if !tt.noAuth {
+				req.SetBasicAuth(tt.username, tt.password)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d'", tt.wantStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain '%s', got '%s'", tt.wantBody, w.Body.String())
+			}
+			if got := w.Header().Get(HeaderWWWAuthenticate); got != tt.wantChallenge {
+				t.Errorf("expected challenge '%s', got '%s'", tt.wantChallenge, got)
+			}
+		})
+	}
+}
+
+func TestBearer(t *testing.T) {
+	errForbidden := webgo.NewHTTPError(http.StatusForbidden, "forbidden", "")
+	router := newRouter(Bearer(&BearerConfig{
+		Validate: func(r *http.Request, token string) (*Identity, error) {
+			switch token {
+			case "valid":
+				return &Identity{Subject: "service"}, nil
+			case "readonly":
+				return nil, errForbidden
+			}
+			return nil, ErrInvalidCredentials
+		},
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantBody      string
+		wantChallenge string
+	}{
+		{name: "valid", authorization: "Bearer valid", wantStatus: http.StatusOK, wantBody: "Bearer:service"},
+		{name: "case insensitive scheme", authorization: "bearer valid", wantStatus: http.StatusOK, wantBody: "Bearer:service"},
+		{name: "invalid", authorization: "Bearer invalid", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer realm="Restricted", error="invalid_token"`},
+		{name: "missing", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer realm="Restricted"`},
+		{name: "other scheme", authorization: "Basic dmFsaWQ=", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer realm="Restricted"`},
+		{name: "http error", authorization: "Bearer readonly", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/private", nil)
+			if tt.authorization != "" {
+				req.Header.Set(HeaderAuthorization, tt.authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d'", tt.wantStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain '%s', got '%s'", tt.wantBody, w.Body.String())
+			}
+			if got := w.Header().Get(HeaderWWWAuthenticate); got != tt.wantChallenge {
+				t.Errorf("expected challenge '%s', got '%s'", tt.wantChallenge, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported signing algorithms of JWTs
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+var (
+	// ErrInvalidToken is returned when the token is malformed
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUnsupportedAlgorithm is returned when the token is signed using an algorithm other than
+	// HS256, RS256 & ES256, including "none"
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	// ErrUnknownKey is returned when there's no key for the key ID of the token, or the key is
+	// not applicable for the algorithm of the token
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrInvalidSignature is returned when the signature of the token does not match
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrTokenExpired is returned when the token has expired (exp)
+	ErrTokenExpired = errors.New("token expired")
+	// ErrTokenNotValidYet is returned when the token is used before it's valid (nbf, iat)
+	ErrTokenNotValidYet = errors.New("token not valid yet")
+	// ErrInvalidIssuer is returned when the issuer (iss) does not match
+	ErrInvalidIssuer = errors.New("invalid issuer")
+	// ErrInvalidAudience is returned when the audience (aud) does not have the expected audience
+	ErrInvalidAudience = errors.New("invalid audience")
+	// ErrInvalidJWKS is returned when a JSON Web Key Set cannot be parsed
+	ErrInvalidJWKS = errors.New("invalid JWKS")
+)
+
+// NumericDate is a timestamp of a JWT, the number of seconds since the Unix epoch
+type NumericDate int64
+
+// UnmarshalJSON parses the timestamp, which can have a fractional part
+func (nd *NumericDate) UnmarshalJSON(b []byte) error {
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return err
+	}
+	*nd = NumericDate(f)
+	return nil
+}
+
+// Time returns the timestamp as time.Time
+func (nd NumericDate) Time() time.Time {
+	return time.Unix(int64(nd), 0)
+}
+
+// Audience is the audience of a JWT, which can be a string or an array of strings
+type Audience []string
+
+// UnmarshalJSON parses the audience from a string or an array of strings
+func (aud *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*aud = Audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*aud = Audience(list)
+	return nil
+}
+
+// Contains returns true if the audience has the value
+func (aud Audience) Contains(value string) bool {
+	for _, a := range aud {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims are the claims of a verified JWT
+type Claims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  Audience    `json:"aud,omitempty"`
+	ExpiresAt NumericDate `json:"exp,omitempty"`
+	NotBefore NumericDate `json:"nbf,omitempty"`
+	IssuedAt  NumericDate `json:"iat,omitempty"`
+	ID        string      `json:"jti,omitempty"`
+	// Raw has all the claims of the token, including the custom ones
+	Raw map[string]interface{} `json:"-"`
+}
+
+// header is the JOSE header of a JWT
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// VerifierConfig has the configurations of the JWT verifier
+type VerifierConfig struct {
+	// Keys are the keys for verifying the signature, by key ID (kid). Key "" is used for tokens
+	// without a key ID, and if there's only one key, it's used for all tokens. The keys are
+	// []byte for HS256, *rsa.PublicKey for RS256 & *ecdsa.PublicKey (P-256) for ES256
+	Keys map[string]interface{}
+	// JWKSFile is the path to a local JSON Web Key Set file, the keys in it are added to Keys
+	JWKSFile string
+	// Issuer if not empty, the issuer (iss) of the tokens should match it
+	Issuer string
+	// Audience if not empty, the audience (aud) of the tokens should have it
+	Audience string
+	// ClockSkew is the leeway allowed when validating exp, nbf & iat, for clock differences
+	// between the issuer & the app
+	ClockSkew time.Duration
+}
+
+// Verifier verifies JWTs signed using HS256, RS256 or ES256
+type Verifier struct {
+	cfg  VerifierConfig
+	keys map[string]interface{}
+	now  func() time.Time
+}
+
+// NewVerifier returns a new Verifier, it returns an error if the JWKS file cannot be loaded
+func NewVerifier(cfg *VerifierConfig) (*Verifier, error) {
+	v := &Verifier{
+		keys: make(map[string]interface{}),
+		now:  time.Now,
+	}
+	if cfg != nil {
+		v.cfg = *cfg
+	}
+
+	if v.cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(v.cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range keys {
+			v.keys[kid] = key
+		}
+	}
+	for kid, key := range v.cfg.Keys {
+		v.keys[kid] = key
+	}
+
+	return v, nil
+}
+
+// key returns the key for the key ID, if it's applicable for the algorithm. The type of the key
+// is checked, so that a public key cannot be used as an HMAC secret
+func (v *Verifier) key(kid string, alg string) (interface{}, error) {
+	key, ok := v.keys[kid]
+	if !ok && kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			key, ok = k, true
+		}
+	}
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	switch k := key.(type) {
+	case []byte:
+		ok = alg == HS256
+	case *rsa.PublicKey:
+		ok = alg == RS256
+	case *ecdsa.PublicKey:
+		ok = alg == ES256 && k.Curve == elliptic.P256()
+	default:
+		ok = false
+	}
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func verifySignature(alg string, key interface{}, input string, sig []byte) error {
+	digest := sha256.Sum256([]byte(input))
+	valid := false
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		valid = hmac.Equal(sig, mac.Sum(nil))
+	case RS256:
+		valid = rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+	case ES256:
+		// the signature is the concatenation of r & s, 32 bytes each
+		if len(sig) == 64 {
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			valid = ecdsa.Verify(key.(*ecdsa.PublicKey), digest[:], r, s)
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func decodeSegment(segment string, dst interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// validate validates the registered claims of the token
+func (v *Verifier) validate(claims *Claims) error {
+	now := v.now()
+	skew := v.cfg.ClockSkew
+
+	if claims.ExpiresAt != 0 && !now.Before(claims.ExpiresAt.Time().Add(skew)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Add(skew).Before(claims.NotBefore.Time()) {
+		return ErrTokenNotValidYet
+	}
+	if claims.IssuedAt != 0 && now.Add(skew).Before(claims.IssuedAt.Time()) {
+		return ErrTokenNotValidYet
+	}
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.cfg.Audience != "" && !claims.Audience.Contains(v.cfg.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// Verify verifies the signature & the claims of the token, and returns the claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	hdr := header{}
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, err
+	}
+	switch hdr.Algorithm {
+	case HS256, RS256, ES256:
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	key, err := v.key(hdr.KeyID, hdr.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	err = verifySignature(hdr.Algorithm, key, parts[0]+"."+parts[1], sig)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, err
+	}
+	if err := decodeSegment(parts[1], &claims.Raw); err != nil {
+		return nil, err
+	}
+
+	err = v.validate(claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Validate verifies the token, it's a ValidatorFunc for the Bearer middleware
+func (v *Verifier) Validate(r *http.Request, token string) (*Identity, error) {
+	claims, err := v.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{Scheme: SchemeBearer, Subject: claims.Subject, Claims: claims}, nil
+}
+
+// jwk is a JSON Web Key (RFC 7517)
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+	// symmetric
+	K string `json:"k"`
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, ErrInvalidJWKS
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func (k *jwk) key() (interface{}, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, ErrInvalidJWKS
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWKS, k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !pub.Curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("%w: point is not on the curve", ErrInvalidJWKS)
+		}
+		return pub, nil
+	case "oct":
+		raw, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(raw) == 0 {
+			return nil, ErrInvalidJWKS
+		}
+		return raw, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidJWKS, k.KeyType)
+}
+
+// ParseJWKS parses a JSON Web Key Set, and returns the keys by key ID. Keys which are not meant
+// for signatures (use other than "sig") are skipped
+func ParseJWKS(data []byte) (map[string]interface{}, error) {
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	err := json.Unmarshal(data, &set)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidJWKS, err.Error())
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for i := range set.Keys {
+		k := &set.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.key()
+		if err != nil {
+			return nil, err
+		}
+		keys[k.KeyID] = key
+	}
+	return keys, nil
+}
+
+// LoadJWKS loads the JSON Web Key Set from a local file, refer ParseJWKS
+func LoadJWKS(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
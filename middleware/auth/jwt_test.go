@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	testNow    = time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	hmacSecret = []byte("secret")
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func sign(t *testing.T, alg string, kid string, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	hdr, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	input := b64(hdr) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case RS256:
+		s, err := rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = s
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return input + "." + b64(sig)
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewVerifier(&VerifierConfig{
+		Keys: map[string]interface{}{
+			"hs": hmacSecret,
+			"rs": &rsaKey.PublicKey,
+			"es": &ecKey.PublicKey,
+		},
+		Issuer:    "webgo",
+		Audience:  "api",
+		ClockSkew: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return testNow }
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":   "webgo",
+			"sub":   "alice",
+			"aud":   []string{"web", "api"},
+			"exp":   testNow.Add(time.Hour).Unix(),
+			"nbf":   testNow.Unix(),
+			"scope": "read",
+		}
+		for k, value := range overrides {
+			if value == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = value
+		}
+		return c
+	}
+	hsNone := b64([]byte(`{"alg":"none","kid":"hs"}`)) + "." + b64([]byte(`{"sub":"alice"}`)) + "."
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "HS256", token: sign(t, HS256, "hs", hmacSecret, claims(nil))},
+		{name: "RS256", token: sign(t, RS256, "rs", rsaKey, claims(nil))},
+		{name: "ES256", token: sign(t, ES256, "es", ecKey, claims(nil))},
+		{name: "audience string", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"aud": "api"}))},
+		{name: "expired within skew", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"exp": testNow.Add(-30 * time.Second).Unix()}))},
+		{name: "expired", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"exp": testNow.Add(-2 * time.Minute).Unix()})), wantErr: ErrTokenExpired},
+		{name: "not valid yet", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"nbf": testNow.Add(2 * time.Minute).Unix()})), wantErr: ErrTokenNotValidYet},
+		{name: "issuer", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"iss": "other"})), wantErr: ErrInvalidIssuer},
+		{name: "audience", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"aud": "web"})), wantErr: ErrInvalidAudience},
+		{name: "no audience", token: sign(t, HS256, "hs", hmacSecret, claims(map[string]interface{}{"aud": nil})), wantErr: ErrInvalidAudience},
+		{name: "wrong secret", token: sign(t, HS256, "hs", []byte("other"), claims(nil)), wantErr: ErrInvalidSignature},
+		{name: "unknown key", token: sign(t, HS256, "unknown", hmacSecret, claims(nil)), wantErr: ErrUnknownKey},
+		// the RSA public key must not be usable as an HMAC secret
+		{name: "algorithm confusion", token: sign(t, HS256, "rs", hmacSecret, claims(nil)), wantErr: ErrUnknownKey},
+		{name: "none", token: hsNone, wantErr: ErrUnsupportedAlgorithm},
+		{name: "malformed", token: "abc.def", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := v.Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error '%v', got '%v'", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if c.Subject != "alice" || c.Raw["scope"] != "read" || !c.Audience.Contains("api") {
+				t.Errorf("unexpected claims %+v", c)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rs","use":"sig","n":%q,"e":%q},
+		{"kty":"EC","kid":"es","crv":"P-256","x":%q,"y":%q},
+		{"kty":"oct","kid":"hs","k":%q},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"","e":""}
+	]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.Bytes()), b64(ecKey.Y.Bytes()),
+		b64(hmacSecret),
+	)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(path, []byte(jwks), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewVerifier(&VerifierConfig{JWKSFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.keys["enc"]; ok {
+		t.Error("expected the encryption key to be skipped")
+	}
+
+	claims := map[string]interface{}{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	for _, token := range []string{
+		sign(t, RS256, "rs", rsaKey, claims),
+		sign(t, ES256, "es", ecKey, claims),
+		sign(t, HS256, "hs", hmacSecret, claims),
+	} {
+		if _, err := v.Verify(token); err != nil {
+			t.Errorf("expected token to be verified, got '%v'", err)
+		}
+	}
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"EC","crv":"P-384","x":"AA","y":"AA"}]}`))
+	if !errors.Is(err, ErrInvalidJWKS) {
+		t.Errorf("expected ErrInvalidJWKS, got '%v'", err)
+	}
+	_, err = NewVerifier(&VerifierConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	if err == nil {
+		t.Error("expected error for a missing JWKS file")
+	}
+}
+
+func TestJWT(t *testing.T) {
+	v, err := NewVerifier(&VerifierConfig{Keys: map[string]interface{}{"": hmacSecret}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newRouter(JWT(v, "api"))
+
+	token := sign(t, HS256, "", hmacSecret, map[string]interface{}{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer "+token)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status '%d', got '%d': %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	expired := sign(t, HS256, "", hmacSecret, map[string]interface{}{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()})
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer "+expired)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status '%d', got '%d'", http.StatusUnauthorized, w.Code)
+	}
+	if got := w.Header().Get(HeaderWWWAuthenticate); got != `Bearer realm="api", error="invalid_token"` {
+		t.Errorf("unexpected challenge '%s'", got)
+	}
+
+	handler := JWT(v, "")
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer "+token)
+	handler(w, req, func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetClaims(r)
+		if !ok || claims.Subject != "alice" {
+			t.Errorf("expected claims in the request context, got %+v", claims)
+		}
+	})
+}
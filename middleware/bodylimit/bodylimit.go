@@ -0,0 +1,206 @@
+/*
+Package bodylimit provides middleware to limit the size of the request body, and to restrict the
+content types of the request body. Requests with a body larger than the limit are responded with
+413 (webgo.ErrRequestTooLarge), and the ones with an unexpected content type with 415, using the
+router's ErrorHandler. Both can be configured per route using the route's metadata, e.g.
+
+	&webgo.Route{
+		Name: "upload",
+		Meta: map[string]interface{}{
+			bodylimit.MetaKey:         int64(32 << 20),
+			bodylimit.ConsumesMetaKey: []string{"multipart/form-data"},
+		},
+	}
+*/
+package bodylimit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	// MetaKey is the key of the route metadata, with the body limit (in bytes) of the route.
+	// A negative limit disables the limit for the route
+	MetaKey = "bodylimit"
+	// ConsumesMetaKey is the key of the route metadata, with the content types ([]string)
+	// accepted by the route
+	ConsumesMetaKey = "consumes"
+
+	// DefaultLimit is the default body limit, 4 MB
+	DefaultLimit = 4 << 20
+
+	headerAcceptPost  = "Accept-Post"
+	headerAcceptPatch = "Accept-Patch"
+	headerConnection  = "Connection"
+
+	// defaultMediaType is the media type of a body without a content type, as per RFC 9110, 8.3
+	defaultMediaType = "application/octet-stream"
+)
+
+// ErrUnsupportedMediaType is responded when the content type of the body is not accepted
+var ErrUnsupportedMediaType = webgo.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported_media_type", "")
+
+// Config has the configurations of the body limit middleware
+type Config struct {
+	// Limit is the maximum size of the request body in bytes, for routes without a limit in the
+	// metadata. Default is 4 MB, and a negative value disables the limit
+	Limit int64
+}
+
+// routeLimit returns the limit of the route from the metadata, if available
+func routeLimit(r *http.Request) (int64, bool) {
+	cp := webgo.Context(r)
+	if cp == nil || cp.Route == nil {
+		return 0, false
+	}
+
+	switch l := cp.Route.Meta[MetaKey].(type) {
+	case int64:
+		return l, true
+	case int:
+		return int64(l), true
+	}
+	return 0, false
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// limitedBody records if the body is read beyond the limit
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (lb *limitedBody) Read(p []byte) (int, error) {
+	n, err := lb.ReadCloser.Read(p)
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		lb.exceeded = true
+	}
+	return n, err
+}
+
+// BodyLimit returns the body limit middleware. Requests with a Content-Length larger than the limit
+// are rejected right away, otherwise the body is limited using http.MaxBytesReader. If a handler
+// reads beyond the limit and does not respond, the request is responded with 413. Handlers
+// returning the read error (e.g. from webgo.Bind) are responded with 413 as well
+func BodyLimit(cfg *Config) webgo.Middleware {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Limit == 0 {
+		c.Limit = DefaultLimit
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		limit := c.Limit
+		if l, ok := routeLimit(req); ok {
+			limit = l
+		}
+		if limit < 0 || !hasBody(req) {
+			next(rw, req)
+			return
+		}
+
+		if req.ContentLength > limit {
+			// the connection is closed, instead of reading the rest of the body
+			rw.Header().Set(headerConnection, "close")
+			webgo.HandleError(rw, req, webgo.ErrRequestTooLarge)
+			return
+		}
+
+		// the original response writer lets net/http close the connection once the limit is hit
+		w := webgo.OriginalResponseWriter(rw)
+		if w == nil {
+			w = rw
+		}
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, req.Body, limit)}
+		req.Body = body
+
+		next(rw, req)
+
+		if !body.exceeded {
+			return
+		}
+		if info, ok := webgo.ResponseInfo(rw); ok && !info.HeaderWritten {
+			webgo.HandleError(rw, req, webgo.ErrRequestTooLarge)
+		}
+	}
+}
+
+// routeConsumes returns the content types accepted by the route from the metadata, if available
+func routeConsumes(r *http.Request) ([]string, bool) {
+	cp := webgo.Context(r)
+	if cp == nil || cp.Route == nil {
+		return nil, false
+	}
+	types, ok := cp.Route.Meta[ConsumesMetaKey].([]string)
+	return types, ok
+}
+
+// accepted returns true if the media type matches any of the types, which can have wildcards.
+// e.g. "text/*", "*/*"
+func accepted(mediaType string, types []string) bool {
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == "*/*", t == mediaType:
+			return true
+		case strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// Consumes returns a middleware which responds with 415 if the content type of the request body is
+// not one of the types, e.g. "application/json", "multipart/form-data", "text/*". The types can be
+// overridden per route using the metadata. Requests without a body are not checked, and a body
+// without a content type is considered application/octet-stream
+func Consumes(types ...string) webgo.Middleware {
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		allowed := types
+		if t, ok := routeConsumes(req); ok {
+			allowed = t
+		}
+		if len(allowed) == 0 || !hasBody(req) {
+			next(rw, req)
+			return
+		}
+
+		ctype := req.Header.Get(webgo.HeaderContentType)
+		mediaType := defaultMediaType
+		var err error
+		if ctype != "" {
+			mediaType, _, err = mime.ParseMediaType(ctype)
+		}
+		if err == nil && accepted(mediaType, allowed) {
+			next(rw, req)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodPost:
+			rw.Header().Set(headerAcceptPost, strings.Join(allowed, ", "))
+		case http.MethodPatch:
+			rw.Header().Set(headerAcceptPatch, strings.Join(allowed, ", "))
+		}
+		herr := &webgo.HTTPError{
+			Status:  ErrUnsupportedMediaType.Status,
+			Code:    ErrUnsupportedMediaType.Code,
+			Message: fmt.Sprintf("unsupported content type '%s'", ctype),
+			Cause:   err,
+		}
+		webgo.HandleError(rw, req, herr)
+	}
+}
@@ -0,0 +1,126 @@
+package bodylimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+func newRouter() *webgo.Router {
+	read := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			// the error is ignored, the middleware responds
+			return
+		}
+		webgo.R200(w, len(body))
+	}
+	router := webgo.NewRouter(&webgo.Config{},
+		&webgo.Route{
+			Name:     "read",
+			Method:   http.MethodPost,
+			Pattern:  "/read",
+			Handlers: []http.HandlerFunc{read},
+		},
+		&webgo.Route{
+			Name:    "bind",
+			Method:  http.MethodPost,
+			Pattern: "/bind",
+			HandlersE: []webgo.HandlerFuncE{func(w http.ResponseWriter, r *http.Request) error {
+				payload := struct {
+					Name string `json:"name"`
+				}{}
+				err := webgo.Bind(r, &payload)
+				if err != nil {
+					return err
+				}
+				webgo.R200(w, payload.Name)
+				return nil
+			}},
+		},
+		&webgo.Route{
+			Name:    "upload",
+			Method:  http.MethodPost,
+			Pattern: "/upload",
+			Meta: map[string]interface{}{
+				MetaKey:         int64(64),
+				ConsumesMetaKey: []string{"multipart/form-data", "text/*"},
+			},
+			Handlers: []http.HandlerFunc{read},
+		},
+		&webgo.Route{
+			Name:     "unlimited",
+			Method:   http.MethodPost,
+			Pattern:  "/unlimited",
+			Meta:     map[string]interface{}{MetaKey: -1},
+			Handlers: []http.HandlerFunc{read},
+		},
+	)
+	router.Use(
+		Consumes(webgo.JSONContentType),
+		BodyLimit(&Config{Limit: 16}),
+	)
+	router.SetupMiddleware()
+	return router
+}
+
+func TestBodyLimit(t *testing.T) {
+	router := newRouter()
+
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		contentType   string
+		unknownLength bool
+		wantStatus    int
+		wantCode      string
+		wantAccept    string
+	}{
+		{name: "within limit", path: "/read", body: `{"name":"a"}`, wantStatus: http.StatusOK},
+		{name: "content length", path: "/read", body: strings.Repeat("a", 17), wantStatus: http.StatusRequestEntityTooLarge, wantCode: "request_too_large"},
+		{name: "unknown length", path: "/read", body: strings.Repeat("a", 17), unknownLength: true, wantStatus: http.StatusRequestEntityTooLarge, wantCode: "request_too_large"},
+		{name: "bind", path: "/bind", body: `{"name":"` + strings.Repeat("a", 20) + `"}`, unknownLength: true, wantStatus: http.StatusRequestEntityTooLarge, wantCode: "request_too_large"},
+		{name: "route limit", path: "/upload", body: strings.Repeat("a", 60), contentType: "text/plain", wantStatus: http.StatusOK},
+		{name: "route limit exceeded", path: "/upload", body: strings.Repeat("a", 65), contentType: "text/plain", wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "unlimited", path: "/unlimited", body: strings.Repeat("a", 1024), wantStatus: http.StatusOK},
+		{name: "unsupported", path: "/read", body: "a", contentType: "text/plain", wantStatus: http.StatusUnsupportedMediaType, wantCode: "unsupported_media_type", wantAccept: webgo.JSONContentType},
+		{name: "missing content type", path: "/read", body: "a", contentType: "-", wantStatus: http.StatusUnsupportedMediaType, wantAccept: webgo.JSONContentType},
+		{name: "invalid content type", path: "/read", body: "a", contentType: "application/", wantStatus: http.StatusUnsupportedMediaType, wantAccept: webgo.JSONContentType},
+		{name: "content type params", path: "/read", body: "{}", contentType: "Application/JSON; charset=utf-8", wantStatus: http.StatusOK},
+		{name: "route content types", path: "/upload", body: "{}", wantStatus: http.StatusUnsupportedMediaType, wantAccept: "multipart/form-data, text/*"},
+		{name: "wildcard", path: "/upload", body: "a", contentType: "text/csv", wantStatus: http.StatusOK},
+		{name: "no body", path: "/read", contentType: "text/plain", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			switch tt.contentType {
+			case "":
+				req.Header.Set(webgo.HeaderContentType, webgo.JSONContentType)
+			case "-":
+			default:
+				req.Header.Set(webgo.HeaderContentType, tt.contentType)
+			}
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d': %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantCode) {
+				t.Errorf("expected code '%s' in the body, got '%s'", tt.wantCode, w.Body.String())
+			}
+			if got := w.Header().Get(headerAcceptPost); got != tt.wantAccept {
+				t.Errorf("expected Accept-Post '%s', got '%s'", tt.wantAccept, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+/*
+Package compress provides a middleware which compresses the responses, based on the Accept-Encoding
+request header. gzip & deflate are supported by default, and more encodings (e.g. brotli, zstd) can
+be added by implementing Encoder. Small responses, responses which are already compressed and
+content types which are not compressible (e.g. images, videos, archives) are not compressed.
+*/
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	headerAcceptEncoding  = "Accept-Encoding"
+	headerContentEncoding = "Content-Encoding"
+	headerContentLength   = "Content-Length"
+	headerContentRange    = "Content-Range"
+
+	// defaultMinLength is the default minimum size of a response body to be compressed
+	defaultMinLength = 1024
+)
+
+// defaultExcludedContentTypes are the content types which are either already compressed,
+// or do not benefit from compression
+var defaultExcludedContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+// compressibleImages are the image types which benefit from compression
+var compressibleImages = []string{"image/svg+xml", "image/x-icon", "image/bmp"}
+
+// Writer is a writer which compresses the data written to it
+type Writer interface {
+	io.WriteCloser
+	// Flush writes any pending compressed data to the underlying writer
+	Flush() error
+}
+
+// Encoder creates compressing writers for a content coding
+type Encoder interface {
+	// Encoding is the name of the content coding, e.g. "br", as used in Accept-Encoding
+	Encoding() string
+	// NewWriter returns a Writer which compresses the data, and writes it to w
+	NewWriter(w io.Writer) Writer
+}
+
+// Config has the configurations of the compress middleware
+type Config struct {
+	// Level is the compression level of gzip & deflate (refer compress/flate), default is
+	// flate.DefaultCompression
+	Level int
+	// MinLength is the minimum size (in bytes) of a response body to be compressed, default is 1024.
+	// Responses are always compressed if they are flushed before reaching MinLength, e.g. streams
+	MinLength int
+	// Encoders are the additional encoders (e.g. brotli, zstd). If the client accepts multiple
+	// encodings with the same preference, Encoders are preferred over gzip & deflate, in the same order
+	Encoders []Encoder
+	// ExcludedContentTypes are the prefixes of the content types which are not compressed. Default
+	// is images (except SVG, ICO & BMP), videos, audio, fonts, archives & PDFs
+	ExcludedContentTypes []string
+}
+
+// pooledEncoder is an Encoder of compress/* writers, which are pooled
+type pooledEncoder struct {
+	encoding string
+	pool     sync.Pool
+}
+
+func (pe *pooledEncoder) Encoding() string {
+	return pe.encoding
+}
+
+func (pe *pooledEncoder) NewWriter(w io.Writer) Writer {
+	pw := pe.pool.Get().(*pooledWriter)
+	pw.reset(w)
+	return pw
+}
+
+// pooledWriter returns the writer to the pool when closed
+type pooledWriter struct {
+	Writer
+	reset func(io.Writer)
+	pool  *sync.Pool
+}
+
+func (pw *pooledWriter) Close() error {
+	err := pw.Writer.Close()
+	pw.pool.Put(pw)
+	return err
+}
+
+func newGzipEncoder(level int) *pooledEncoder {
+	pe := &pooledEncoder{encoding: "gzip"}
+	pe.pool.New = func() interface{} {
+		gw, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			gw = gzip.NewWriter(io.Discard)
+		}
+		return &pooledWriter{Writer: gw, reset: gw.Reset, pool: &pe.pool}
+	}
+	return pe
+}
+
+func newDeflateEncoder(level int) *pooledEncoder {
+	pe := &pooledEncoder{encoding: "deflate"}
+	pe.pool.New = func() interface{} {
+		fw, err := flate.NewWriter(io.Discard, level)
+		if err != nil {
+			fw, _ = flate.NewWriter(io.Discard, flate.DefaultCompression)
+		}
+		return &pooledWriter{Writer: fw, reset: fw.Reset, pool: &pe.pool}
+	}
+	return pe
+}
+
+// acceptedEncodings returns the q-value of all the encodings in the Accept-Encoding header
+func acceptedEncodings(header string) map[string]float64 {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+		accepted[name] = q
+	}
+	return accepted
+}
+
+// negotiate returns the encoder with the highest preference of the client, encoders are in the
+// order of preference of the server. It returns nil if none of them are acceptable
+func negotiate(header string, encoders []Encoder) Encoder {
+	if header == "" {
+		return nil
+	}
+
+	accepted := acceptedEncodings(header)
+	var (
+		best        Encoder
+		bestQuality float64
+	)
+	for _, enc := range encoders {
+		q, ok := accepted[enc.Encoding()]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if !ok || q <= bestQuality {
+			continue
+		}
+		best, bestQuality = enc, q
+	}
+	return best
+}
+
+func excluded(contentType string, excludedTypes []string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, ct := range compressibleImages {
+		if strings.HasPrefix(contentType, ct) {
+			return false
+		}
+	}
+	for _, ct := range excludedTypes {
+		if strings.HasPrefix(contentType, ct) {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary adds value to the Vary header, unless it already has it
+func addVary(header http.Header, value string) {
+	for _, v := range header.Values(webgo.HeaderVary) {
+		for _, existing := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), value) {
+				return
+			}
+		}
+	}
+	header.Add(webgo.HeaderVary, value)
+}
+
+// compressWriter holds the response till it has MinLength bytes, and then decides whether to
+// compress it or not
+type compressWriter struct {
+	http.ResponseWriter
+	enc      Encoder
+	cfg      *Config
+	excluded []string
+
+	status      int
+	wroteHeader bool
+	decided     bool
+	cw          Writer
+	buf         []byte
+}
+
+// WriteHeader records the status code, the header is written once it's decided whether
+// the response would be compressed
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+
+	// responses without a body are written as is
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decided = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// decide decides whether to compress the response, and writes the response header. force is true
+// when the response is flushed or completed before reaching MinLength
+func (w *compressWriter) decide(force bool) {
+	w.decided = true
+	header := w.Header()
+
+	if header.Get(webgo.HeaderContentType) == "" && len(w.buf) > 0 {
+		// the content type should be detected before compression, otherwise net/http would
+		// detect the type of the compressed data
+		header.Set(webgo.HeaderContentType, http.DetectContentType(w.buf))
+	}
+
+	// the ranges of partial responses (incl. multipart/byteranges) refer to the uncompressed content
+	compress := w.status != http.StatusPartialContent &&
+		header.Get(headerContentRange) == "" &&
+		header.Get(headerContentEncoding) == "" &&
+		!excluded(header.Get(webgo.HeaderContentType), w.excluded) &&
+		(force || len(w.buf) >= w.cfg.MinLength)
+	if cl, err := strconv.Atoi(header.Get(headerContentLength)); err == nil && cl < w.cfg.MinLength {
+		compress = false
+	}
+
+	if compress {
+		header.Set(headerContentEncoding, w.enc.Encoding())
+		header.Del(headerContentLength)
+		// the compressed response is not byte-for-byte same as the uncompressed one
+		if etag := header.Get(webgo.HeaderETag); strings.HasPrefix(etag, `"`) {
+			header.Set(webgo.HeaderETag, "W/"+etag)
+		}
+		w.cw = w.enc.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *compressWriter) Write(body []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.decided {
+		w.buf = append(w.buf, body...)
+		if len(w.buf) < w.cfg.MinLength {
+			return len(body), nil
+		}
+		w.decide(false)
+		err := w.writeBuffer()
+		return len(body), err
+	}
+
+	if w.cw != nil {
+		return w.cw.Write(body)
+	}
+	return w.ResponseWriter.Write(body)
+}
+
+func (w *compressWriter) writeBuffer() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.cw != nil {
+		_, err = w.cw.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+// Flush writes the response held so far, compressing it if applicable, and flushes it to
+// the client. It's required for streams like Server-Sent Events
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		w.decide(true)
+		_ = w.writeBuffer()
+	}
+	if w.cw != nil {
+		_ = w.cw.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// close completes the response, any response held is written & the compressing writer is closed
+func (w *compressWriter) close() error {
+	if !w.wroteHeader {
+		if len(w.buf) == 0 {
+			// nothing was written by the handlers
+			return nil
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		w.decide(false)
+		err := w.writeBuffer()
+		if err != nil {
+			return err
+		}
+	}
+	if w.cw != nil {
+		return w.cw.Close()
+	}
+	return nil
+}
+
+// Hijack implements the http.Hijacker interface
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, errors.New("unable to create hijacker")
+}
+
+// Unwrap returns the underlying response writer, so that webgo.ResponseInfo & http.ResponseController
+// work with the compressed response
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Compress returns the compress middleware
+func Compress(cfg *Config) webgo.Middleware {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Level == 0 {
+		c.Level = flate.DefaultCompression
+	}
+	if c.MinLength <= 0 {
+		c.MinLength = defaultMinLength
+	}
+	excludedTypes := c.ExcludedContentTypes
+	if excludedTypes == nil {
+		excludedTypes = defaultExcludedContentTypes
+	}
+
+	encoders := make([]Encoder, 0, len(c.Encoders)+2)
+	encoders = append(encoders, c.Encoders...)
+	encoders = append(encoders, newGzipEncoder(c.Level), newDeflateEncoder(c.Level))
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		addVary(rw.Header(), headerAcceptEncoding)
+
+		enc := negotiate(req.Header.Get(headerAcceptEncoding), encoders)
+		if enc == nil || req.Method == http.MethodHead {
+			next(rw, req)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: rw,
+			enc:            enc,
+			cfg:            &c,
+			excluded:       excludedTypes,
+		}
+		next(cw, req)
+		err := cw.close()
+		if err != nil {
+			webgo.RequestLogger(req).Error(err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+var largeBody = strings.Repeat("hello world ", 200)
+
+// identityEncoder is a custom encoder, which does not compress
+type identityEncoder struct{}
+
+type nopWriter struct {
+	io.Writer
+}
+
+func (nopWriter) Close() error { return nil }
+func (nopWriter) Flush() error { return nil }
+
+func (identityEncoder) Encoding() string { return "custom" }
+func (identityEncoder) NewWriter(w io.Writer) Writer {
+	return nopWriter{Writer: w}
+}
+
+func TestNegotiate(t *testing.T) {
+	gz, df := newGzipEncoder(flate.DefaultCompression), newDeflateEncoder(flate.DefaultCompression)
+	encoders := []Encoder{identityEncoder{}, gz, df}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "gzip, deflate", want: "gzip"},
+		{header: "gzip;q=0.5, deflate", want: "deflate"},
+		{header: "custom, gzip", want: "custom"},
+		{header: "*", want: "custom"},
+		{header: "*;q=0.1, gzip;q=0", want: "custom"},
+		{header: "gzip;q=0, deflate;q=0", want: ""},
+		{header: "identity", want: ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if enc := negotiate(tt.header, encoders); enc != nil {
+			got = enc.Encoding()
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected '%s', got '%s'", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	var status int
+	router := webgo.NewRouter(&webgo.Config{},
+		&webgo.Route{
+			Name:    "large",
+			Method:  http.MethodGet,
+			Pattern: "/large",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					webgo.SetETag(w, "v1", false)
+					webgo.SendResponse(w, largeBody, http.StatusCreated)
+				},
+			},
+		},
+		&webgo.Route{
+			Name:    "small",
+			Method:  http.MethodGet,
+			Pattern: "/small",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					webgo.R200(w, "hello")
+				},
+			},
+		},
+		&webgo.Route{
+			Name:    "image",
+			Method:  http.MethodGet,
+			Pattern: "/image",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					webgo.Send(w, "image/png", largeBody, http.StatusOK)
+				},
+			},
+		},
+		&webgo.Route{
+			Name:    "stream",
+			Method:  http.MethodGet,
+			Pattern: "/stream",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set(webgo.HeaderContentType, "text/event-stream")
+					_, _ = w.Write([]byte("data: 1\n\n"))
+					w.(http.Flusher).Flush()
+					_, _ = w.Write([]byte("data: 2\n\n"))
+				},
+			},
+		},
+	)
+	router.Use(
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(w, r)
+			status = webgo.ResponseStatus(w)
+		},
+		Compress(nil),
+	)
+	router.SetupMiddleware()
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+		wantBody       string
+		wantStatus     int
+	}{
+		{name: "gzip", path: "/large", acceptEncoding: "gzip", wantEncoding: "gzip", wantBody: largeBody, wantStatus: http.StatusCreated},
+		{name: "deflate", path: "/large", acceptEncoding: "deflate;q=1, gzip;q=0.5", wantEncoding: "deflate", wantBody: largeBody, wantStatus: http.StatusCreated},
+		{name: "not accepted", path: "/large", acceptEncoding: "", wantBody: largeBody, wantStatus: http.StatusCreated},
+		{name: "small", path: "/small", acceptEncoding: "gzip", wantBody: "hello", wantStatus: http.StatusOK},
+		{name: "image", path: "/image", acceptEncoding: "gzip", wantBody: largeBody, wantStatus: http.StatusOK},
+		{name: "stream", path: "/stream", acceptEncoding: "gzip", wantEncoding: "gzip", wantBody: "data: 1\n\ndata: 2\n\n", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(headerAcceptEncoding, tt.acceptEncoding)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus || status != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d', '%d'", tt.wantStatus, w.Code, status)
+			}
+			if got := w.Header().Get(headerContentEncoding); got != tt.wantEncoding {
+				t.Errorf("expected encoding '%s', got '%s'", tt.wantEncoding, got)
+			}
+			if !strings.Contains(w.Header().Get(webgo.HeaderVary), headerAcceptEncoding) {
+				t.Errorf("expected Vary to have Accept-Encoding, got '%s'", w.Header().Get(webgo.HeaderVary))
+			}
+
+			var body io.Reader = w.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				gr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gr
+				if tt.path == "/large" && w.Header().Get(webgo.HeaderETag) != `W/"v1"` {
+					t.Errorf("expected weak ETag, got '%s'", w.Header().Get(webgo.HeaderETag))
+				}
+			case "deflate":
+				body = flate.NewReader(w.Body)
+			}
+			raw, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(raw), tt.wantBody) {
+				t.Errorf("expected body to contain '%s', got '%s'", tt.wantBody, string(raw))
+			}
+		})
+	}
+}
+
+func TestCompressRange(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000)
+	router := webgo.NewRouter(&webgo.Config{}, &webgo.Route{
+		Name:    "file",
+		Method:  http.MethodGet,
+		Pattern: "/file",
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				webgo.SendFile(w, r, "", strings.NewReader(content), time.Time{}, &webgo.FileOptions{ContentType: "text/plain"})
+			},
+		},
+	})
+	router.Use(Compress(nil))
+	router.SetupMiddleware()
+
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		wantStatus   int
+		wantEncoding string
+		wantBody     string
+	}{
+		{name: "complete", wantStatus: http.StatusOK, wantEncoding: "gzip", wantBody: content},
+		{name: "single range", rangeHeader: "bytes=0-4999", wantStatus: http.StatusPartialContent, wantBody: content[:5000]},
+		{name: "multiple ranges", rangeHeader: "bytes=0-999,5000-5999", wantStatus: http.StatusPartialContent, wantBody: content[5000:6000]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/file", nil)
+			req.Header.Set(headerAcceptEncoding, "gzip")
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d'", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get(headerContentEncoding); got != tt.wantEncoding {
+				t.Errorf("expected encoding '%s', got '%s'", tt.wantEncoding, got)
+			}
+
+			var body io.Reader = w.Body
+			if tt.wantEncoding == "gzip" {
+				gr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gr
+			}
+			raw, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(raw), tt.wantBody) {
+				t.Errorf("expected body to contain the requested range, got %d bytes", len(raw))
+			}
+		})
+	}
+}
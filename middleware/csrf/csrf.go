@@ -0,0 +1,282 @@
+/*
+Package csrf provides a middleware which protects against Cross-Site Request Forgery. A token is
+issued to every client, and is stored in a cookie (double-submit cookie) or the session of the app
+(synchronizer token), refer Store. Requests with unsafe methods (e.g. POST) should submit the token
+in a form field or a header, and their Origin (or Referer) should be the same as the host.
+
+The token is available to the templates rendered using webgo Views as {{csrf_token}} & {{csrf_field}},
+and using Token & TemplateField otherwise. The token is masked with a random value for every
+request, so that it cannot be extracted from compressed responses (BREACH). API routes which do not
+use cookies can be exempted using the route's metadata, e.g.
+
+	&webgo.Route{
+		Name: "webhook",
+		Meta: map[string]interface{}{
+			csrf.MetaKey: csrf.Exempt,
+		},
+	}
+*/
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	// MetaKey is the key of the route metadata, to exempt the route from CSRF protection
+	MetaKey = "csrf"
+	// Exempt is the value of MetaKey in the route metadata, to exempt the route
+	Exempt = false
+
+	// HeaderCSRFToken is the default request header with the token
+	HeaderCSRFToken = "X-CSRF-Token"
+	// FieldName is the default form field with the token
+	FieldName = "csrf_token"
+
+	headerOrigin       = "Origin"
+	headerReferer      = "Referer"
+	headerSecFetchSite = "Sec-Fetch-Site"
+	headerCookie       = "Cookie"
+
+	tokenLength = 32
+)
+
+type ctxkey string
+
+const tokenKey = ctxkey("csrftoken")
+
+var (
+	// ErrInvalidToken is responded when the token of an unsafe request is missing or invalid
+	ErrInvalidToken = webgo.NewHTTPError(http.StatusForbidden, "csrf_token_invalid", "")
+	// ErrInvalidOrigin is responded when an unsafe request is from a different origin
+	ErrInvalidOrigin = webgo.NewHTTPError(http.StatusForbidden, "csrf_origin_invalid", "")
+)
+
+// Config has the configurations of the CSRF middleware
+type Config struct {
+	// Store keeps the token of the client, default is a CookieStore with the default configuration
+	Store Store
+	// FieldName is the form field with the token, default is "csrf_token"
+	FieldName string
+	// HeaderName is the request header with the token, default is X-CSRF-Token
+	HeaderName string
+	// TrustedOrigins are the hosts (e.g. "app.example.com") other than the host of the request,
+	// from which unsafe requests are allowed
+	TrustedOrigins []string
+}
+
+// requestToken is the token of the current request
+type requestToken struct {
+	masked string
+	field  string
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func exempted(r *http.Request) bool {
+	cp := webgo.Context(r)
+	if cp == nil || cp.Route == nil {
+		return false
+	}
+	enabled, ok := cp.Route.Meta[MetaKey].(bool)
+	return ok && !enabled
+}
+
+func newToken() ([]byte, error) {
+	token := make([]byte, tokenLength)
+	_, err := rand.Read(token)
+	return token, err
+}
+
+func encode(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeToken returns the raw token, a masked token is unmasked
+func decodeToken(value string) []byte {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	switch len(raw) {
+	case tokenLength:
+		return raw
+	case 2 * tokenLength:
+		pad, masked := raw[:tokenLength], raw[tokenLength:]
+		token := make([]byte, tokenLength)
+		for i := range token {
+			token[i] = pad[i] ^ masked[i]
+		}
+		return token
+	}
+	return nil
+}
+
+// mask returns the token XOR-ed with a random pad, prefixed with the pad
+func mask(token []byte) (string, error) {
+	raw := make([]byte, 2*tokenLength)
+	pad := raw[:tokenLength]
+	_, err := rand.Read(pad)
+	if err != nil {
+		return "", err
+	}
+	for i := range token {
+		raw[tokenLength+i] = pad[i] ^ token[i]
+	}
+	return encode(raw), nil
+}
+
+// Token returns the masked token of the request, to be submitted with unsafe requests
+func Token(r *http.Request) string {
+	rt, _ := r.Context().Value(tokenKey).(*requestToken)
+	if rt == nil {
+		return ""
+	}
+	return rt.masked
+}
+
+// TemplateField returns a hidden form field with the token of the request
+func TemplateField(r *http.Request) template.HTML {
+	rt, _ := r.Context().Value(tokenKey).(*requestToken)
+	if rt == nil {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(
+		`<input type="hidden" name="%s" value="%s">`,
+		template.HTMLEscapeString(rt.field),
+		template.HTMLEscapeString(rt.masked),
+	))
+}
+
+// sameOrigin returns true if the host of the URL is the host of the request, or is trusted
+func sameOrigin(r *http.Request, rawURL string, trusted []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if r.TLS != nil && u.Scheme != "https" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, host := range trusted {
+		if strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrigin verifies that the request is from the same origin, using Sec-Fetch-Site, Origin &
+// Referer. Referer is required for HTTPS requests without Origin, as browsers always send one of
+// them. Plain HTTP requests without both are allowed, since proxies may remove them
+func checkOrigin(r *http.Request, trusted []string) bool {
+	if r.Header.Get(headerSecFetchSite) == "cross-site" && len(trusted) == 0 {
+		return false
+	}
+	if origin := r.Header.Get(headerOrigin); origin != "" {
+		return sameOrigin(r, origin, trusted)
+	}
+	if referer := r.Header.Get(headerReferer); referer != "" {
+		return sameOrigin(r, referer, trusted)
+	}
+	return r.TLS == nil
+}
+
+// submittedToken returns the token submitted in the header or the form
+func submittedToken(r *http.Request, header string, field string) string {
+	if token := r.Header.Get(header); token != "" {
+		return token
+	}
+	return r.PostFormValue(field)
+}
+
+// CSRF returns the CSRF middleware
+func CSRF(cfg *Config) webgo.Middleware {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Store == nil {
+		c.Store = &CookieStore{}
+	}
+	if c.FieldName == "" {
+		c.FieldName = FieldName
+	}
+	if c.HeaderName == "" {
+		c.HeaderName = HeaderCSRFToken
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		if exempted(req) {
+			next(rw, req)
+			return
+		}
+		// the responses have the token, and should not be cached for other clients
+		rw.Header().Add(webgo.HeaderVary, headerCookie)
+
+		stored, err := c.Store.Get(req)
+		if err != nil {
+			webgo.HandleError(rw, req, err)
+			return
+		}
+		token := decodeToken(stored)
+		if len(token) != tokenLength {
+			token, err = newToken()
+			if err == nil {
+				err = c.Store.Save(rw, req, encode(token))
+			}
+			if err != nil {
+				webgo.HandleError(rw, req, err)
+				return
+			}
+		}
+
+		if !safeMethod(req.Method) {
+			if !checkOrigin(req, c.TrustedOrigins) {
+				webgo.HandleError(rw, req, ErrInvalidOrigin)
+				return
+			}
+			submitted := decodeToken(submittedToken(req, c.HeaderName, c.FieldName))
+			if len(submitted) != tokenLength || subtle.ConstantTimeCompare(submitted, token) != 1 {
+				webgo.HandleError(rw, req, ErrInvalidToken)
+				return
+			}
+		}
+
+		masked, err := mask(token)
+		if err != nil {
+			webgo.HandleError(rw, req, err)
+			return
+		}
+		rt := &requestToken{masked: masked, field: c.FieldName}
+		req = req.WithContext(context.WithValue(req.Context(), tokenKey, rt))
+		webgo.AddTemplateFuncs(req, template.FuncMap{
+			"csrf_token": func() string {
+				return Token(req)
+			},
+			"csrf_field": func() template.HTML {
+				return TemplateField(req)
+			},
+		})
+
+		next(rw, req)
+	}
+}
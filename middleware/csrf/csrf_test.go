@@ -0,0 +1,153 @@
+package csrf
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+var fieldRegexp = regexp.MustCompile(`<input type="hidden" name="csrf_token" value="([^"]+)">`)
+
+func newRouter(t *testing.T) *webgo.Router {
+	t.Helper()
+	views, err := webgo.NewViews(fstest.MapFS{
+		"form.html": &fstest.MapFile{
+			Data: []byte(`<form method="post">{{csrf_field}}</form>`),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		webgo.R200(w, "ok")
+	}
+	router := webgo.NewRouter(&webgo.Config{},
+		&webgo.Route{
+			Name:    "form",
+			Method:  http.MethodGet,
+			Pattern: "/form",
+			Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				webgo.View(w, r, "form", nil, http.StatusOK)
+			}},
+		},
+		&webgo.Route{
+			Name:     "submit",
+			Method:   http.MethodPost,
+			Pattern:  "/form",
+			Handlers: []http.HandlerFunc{ok},
+		},
+		&webgo.Route{
+			Name:     "webhook",
+			Method:   http.MethodPost,
+			Pattern:  "/webhook",
+			Meta:     map[string]interface{}{MetaKey: Exempt},
+			Handlers: []http.HandlerFunc{ok},
+		},
+	)
+	router.Views = views
+	router.Use(CSRF(&Config{TrustedOrigins: []string{"app.example.com"}}))
+	router.SetupMiddleware()
+	return router
+}
+
+func TestCSRF(t *testing.T) {
+	router := newRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/form", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status '%d', got '%d'", http.StatusOK, w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != defaultCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("expected an HttpOnly token cookie, got %+v", cookies)
+	}
+	cookie := cookies[0]
+	match := fieldRegexp.FindStringSubmatch(w.Body.String())
+	if match == nil {
+		t.Fatalf("expected the hidden field in the form, got '%s'", w.Body.String())
+	}
+	token := match[1]
+	if token == cookie.Value {
+		t.Error("expected the token in the form to be masked")
+	}
+
+	// the cookie is reused, and the token is masked differently for every request
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/form", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(w, req)
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("expected the existing cookie to be reused")
+	}
+	if m := fieldRegexp.FindStringSubmatch(w.Body.String()); m == nil || m[1] == token {
+		t.Errorf("expected a differently masked token, got '%v'", m)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		form       string
+		header     map[string]string
+		noCookie   bool
+		tls        bool
+		wantStatus int
+	}{
+		{name: "form field", path: "/form", form: FieldName + "=" + url.QueryEscape(token), wantStatus: http.StatusOK},
+		{name: "header", path: "/form", header: map[string]string{HeaderCSRFToken: token}, wantStatus: http.StatusOK},
+		{name: "unmasked header", path: "/form", header: map[string]string{HeaderCSRFToken: cookie.Value}, wantStatus: http.StatusOK},
+		{name: "same origin", path: "/form", form: FieldName + "=" + token, header: map[string]string{headerOrigin: "http://example.com"}, wantStatus: http.StatusOK},
+		{name: "trusted origin", path: "/form", form: FieldName + "=" + token, header: map[string]string{headerOrigin: "https://app.example.com"}, wantStatus: http.StatusOK},
+		{name: "missing token", path: "/form", wantStatus: http.StatusForbidden},
+		{name: "invalid token", path: "/form", form: FieldName + "=abc", wantStatus: http.StatusForbidden},
+		{name: "missing cookie", path: "/form", form: FieldName + "=" + token, noCookie: true, wantStatus: http.StatusForbidden},
+		{name: "cross origin", path: "/form", form: FieldName + "=" + token, header: map[string]string{headerOrigin: "http://evil.com"}, wantStatus: http.StatusForbidden},
+		{name: "cross origin referer", path: "/form", form: FieldName + "=" + token, header: map[string]string{headerReferer: "http://evil.com/form"}, wantStatus: http.StatusForbidden},
+		{name: "https without referer", path: "/form", form: FieldName + "=" + token, tls: true, wantStatus: http.StatusForbidden},
+		{name: "https with referer", path: "/form", form: FieldName + "=" + token, tls: true, header: map[string]string{headerReferer: "https://example.com/form"}, wantStatus: http.StatusOK},
+		{name: "exempt", path: "/webhook", noCookie: true, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://example.com"+tt.path, strings.NewReader(tt.form))
+			req.Header.Set(webgo.HeaderContentType, "application/x-www-form-urlencoded")
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if !tt.noCookie {
+				req.AddCookie(cookie)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d': %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTemplateField(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if Token(req) != "" || TemplateField(req) != "" {
+		t.Error("expected no token outside the middleware")
+	}
+
+	handler := CSRF(&Config{FieldName: "token"})
+	handler(httptest.NewRecorder(), req, func(w http.ResponseWriter, r *http.Request) {
+		field := string(TemplateField(r))
+		if Token(r) == "" || !strings.Contains(field, `name="token"`) || !strings.Contains(field, Token(r)) {
+			t.Errorf("unexpected token '%s' & field '%s'", Token(r), field)
+		}
+	})
+}
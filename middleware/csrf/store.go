@@ -0,0 +1,84 @@
+package csrf
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultCookieName   = "_csrf"
+	defaultCookieMaxAge = 12 * time.Hour
+)
+
+// Store keeps the token of the client. CookieStore implements the double-submit cookie pattern.
+// For the synchronizer token pattern, implement Store using the server side session of the app
+type Store interface {
+	// Get returns the token of the client, it's empty if the client does not have one
+	Get(r *http.Request) (string, error)
+	// Save saves a new token for the client
+	Save(w http.ResponseWriter, r *http.Request, token string) error
+}
+
+// CookieStore keeps the token in a cookie
+type CookieStore struct {
+	// Name is the name of the cookie, default is "_csrf"
+	Name string
+	// Path is the path of the cookie, default is "/"
+	Path string
+	// Domain is the domain of the cookie, default is the host of the request
+	Domain string
+	// MaxAge is the lifetime of the cookie, default is 12 hours
+	MaxAge time.Duration
+	// Secure if true, the cookie is sent only over HTTPS. It's always set for HTTPS requests
+	Secure bool
+	// SameSite is the SameSite attribute of the cookie, default is Lax
+	SameSite http.SameSite
+	// AllowScripts if true, the cookie is not HttpOnly, so that scripts can read the token from
+	// the cookie & send it in the header
+	AllowScripts bool
+}
+
+func (cs *CookieStore) name() string {
+	if cs.Name == "" {
+		return defaultCookieName
+	}
+	return cs.Name
+}
+
+// Get implements the Store interface
+func (cs *CookieStore) Get(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(cs.name())
+	if err != nil {
+		// http.ErrNoCookie, the client does not have a token yet
+		return "", nil
+	}
+	return cookie.Value, nil
+}
+
+// Save implements the Store interface
+func (cs *CookieStore) Save(w http.ResponseWriter, r *http.Request, token string) error {
+	cookie := &http.Cookie{
+		Name:     cs.name(),
+		Value:    token,
+		Path:     cs.Path,
+		Domain:   cs.Domain,
+		Secure:   cs.Secure || r.TLS != nil,
+		HttpOnly: !cs.AllowScripts,
+		SameSite: cs.SameSite,
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	maxAge := cs.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultCookieMaxAge
+	}
+	cookie.MaxAge = int(maxAge.Seconds())
+	cookie.Expires = time.Now().Add(maxAge)
+
+	http.SetCookie(w, cookie)
+	return nil
+}
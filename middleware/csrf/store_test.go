@@ -0,0 +1,40 @@
+package csrf
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCookieStore(t *testing.T) {
+	cs := &CookieStore{Name: "token", MaxAge: time.Hour, AllowScripts: true, SameSite: http.SameSiteStrictMode}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	err := cs.Save(w, req, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %d", len(cookies))
+	}
+	c := cookies[0]
+	if c.Name != "token" || c.Value != "abc" || c.Path != "/" || c.MaxAge != 3600 ||
+		!c.Secure || c.HttpOnly || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("unexpected cookie %+v", c)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	if got, err := cs.Get(req); got != "" || err != nil {
+		t.Errorf("expected no token, got '%s', '%v'", got, err)
+	}
+	req.AddCookie(c)
+	if got, _ := cs.Get(req); got != "abc" {
+		t.Errorf("expected token 'abc', got '%s'", got)
+	}
+}
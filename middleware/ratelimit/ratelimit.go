@@ -0,0 +1,200 @@
+/*
+Package ratelimit provides a middleware which limits the number of requests, per key (e.g. client
+IP, API key), using the token bucket or sliding window algorithm. The limits can be configured per
+route using the route's metadata, e.g.
+
+	&webgo.Route{
+		Name: "login",
+		Meta: map[string]interface{}{
+			ratelimit.MetaKey: ratelimit.Limit{Requests: 5, Window: time.Minute},
+		},
+	}
+
+The RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset & RateLimit-Policy response headers are
+set as per the IETF draft (draft-ietf-httpapi-ratelimit-headers). Requests exceeding the limit are
+responded with 429 & Retry-After, using the router's ErrorHandler.
+*/
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	// MetaKey is the key of the route metadata, with the Limit for the route
+	MetaKey = "ratelimit"
+
+	headerLimit      = "RateLimit-Limit"
+	headerRemaining  = "RateLimit-Remaining"
+	headerReset      = "RateLimit-Reset"
+	headerPolicy     = "RateLimit-Policy"
+	headerRetryAfter = "Retry-After"
+)
+
+// ErrLimitExceeded is responded when a request exceeds the limit
+var ErrLimitExceeded = webgo.NewHTTPError(http.StatusTooManyRequests, "rate_limit_exceeded", "")
+
+// Algorithm is the rate limiting algorithm
+type Algorithm int
+
+const (
+	// TokenBucket allows bursts of up to Burst requests, and refills at the rate of
+	// Requests per Window
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows Requests in any Window, it is approximated using the count of the
+	// current & previous fixed windows
+	SlidingWindow
+)
+
+// Limit is the number of requests allowed in a window of time
+type Limit struct {
+	Requests int
+	Window   time.Duration
+	// Burst is the maximum number of requests allowed at once, for TokenBucket. Default is Requests
+	Burst     int
+	Algorithm Algorithm
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+func (l Limit) valid() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+
+// KeyFunc returns the key of the request, for which the requests are counted. Requests with an
+// empty key are not limited
+type KeyFunc func(r *http.Request) string
+
+// ByIP returns the IP address of the client, from the remote address of the connection. If the
+// app is behind a proxy, use ByHeader with the header set by the proxy instead
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByHeader returns a KeyFunc which uses the value of the request header, e.g. X-Real-IP
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// ByAPIKey returns a KeyFunc which uses the API key in the request header, e.g. X-API-Key.
+// The "Bearer " prefix is removed, if present
+func ByAPIKey(header string) KeyFunc {
+	return func(r *http.Request) string {
+		value := strings.TrimSpace(r.Header.Get(header))
+		if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+			value = strings.TrimSpace(value[7:])
+		}
+		return value
+	}
+}
+
+// ByRoute returns the name of the route, so that all the requests to a route share the same limit
+func ByRoute(r *http.Request) string {
+	if cp := webgo.Context(r); cp != nil && cp.Route != nil {
+		return cp.Route.Name
+	}
+	return ""
+}
+
+// Config has the configurations of the rate limit middleware
+type Config struct {
+	// Limit is the default limit, for routes without a limit in the metadata. If it's zero, only
+	// the routes with a limit in the metadata are limited
+	Limit Limit
+	// Key returns the key of the request, default is ByIP
+	Key KeyFunc
+	// Store keeps track of the requests, default is an in-memory store
+	Store Store
+}
+
+// routeLimit returns the limit & name of the route from the metadata, if available
+func routeLimit(r *http.Request) (Limit, string, bool) {
+	cp := webgo.Context(r)
+	if cp == nil || cp.Route == nil {
+		return Limit{}, "", false
+	}
+
+	switch l := cp.Route.Meta[MetaKey].(type) {
+	case Limit:
+		return l, cp.Route.Name, true
+	case *Limit:
+		if l != nil {
+			return *l, cp.Route.Name, true
+		}
+	}
+	return Limit{}, "", false
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// RateLimit returns the rate limit middleware. If the store fails, the request is allowed and the
+// error is logged
+func RateLimit(cfg *Config) webgo.Middleware {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Key == nil {
+		c.Key = ByIP
+	}
+	if c.Store == nil {
+		c.Store = NewMemoryStore()
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		limit := c.Limit
+		// routes with their own limit are counted separately
+		scope := "*"
+		if l, name, ok := routeLimit(req); ok {
+			limit, scope = l, name
+		}
+
+		key := c.Key(req)
+		if !limit.valid() || key == "" {
+			next(rw, req)
+			return
+		}
+
+		res, err := c.Store.Take(req.Context(), scope+":"+key, limit)
+		if err != nil {
+			webgo.RequestLogger(req).Error(fmt.Sprintf("ratelimit: %s", err.Error()))
+			next(rw, req)
+			return
+		}
+
+		header := rw.Header()
+		header.Set(headerLimit, strconv.Itoa(limit.Requests))
+		header.Set(headerRemaining, strconv.Itoa(res.Remaining))
+		header.Set(headerReset, seconds(res.Reset))
+		header.Set(headerPolicy, fmt.Sprintf("%d;w=%s", limit.Requests, seconds(limit.Window)))
+
+		if !res.Allowed {
+			header.Set(headerRetryAfter, seconds(res.RetryAfter))
+			webgo.HandleError(rw, req, ErrLimitExceeded)
+			return
+		}
+
+		next(rw, req)
+	}
+}
@@ -0,0 +1,167 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	ms := NewMemoryStore()
+	ms.now = func() time.Time {
+		return *now
+	}
+	return ms
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	ms := newTestStore(&now)
+	limit := Limit{Requests: 2, Window: time.Second, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		res, _ := ms.Take(context.Background(), "key", limit)
+		if !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: expected to be allowed with %d remaining, got %+v", i, 2-i, res)
+		}
+	}
+
+	res, _ := ms.Take(context.Background(), "key", limit)
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("expected to be limited with retry after 500ms, got %+v", res)
+	}
+
+	// 2 tokens per second are refilled
+	now = now.Add(500 * time.Millisecond)
+	res, _ = ms.Take(context.Background(), "key", limit)
+	if !res.Allowed {
+		t.Fatalf("expected to be allowed after refill, got %+v", res)
+	}
+
+	res, _ = ms.Take(context.Background(), "other", limit)
+	if !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("expected a different key to have its own bucket, got %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	ms := newTestStore(&now)
+	limit := Limit{Requests: 4, Window: time.Minute, Algorithm: SlidingWindow}
+
+	for i := 0; i < 4; i++ {
+		res, _ := ms.Take(context.Background(), "key", limit)
+		if !res.Allowed {
+			t.Fatalf("request %d: expected to be allowed, got %+v", i, res)
+		}
+	}
+	res, _ := ms.Take(context.Background(), "key", limit)
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expected to be limited, got %+v", res)
+	}
+
+	// 75% into the next window, the previous window is weighted 0.25, i.e. 1 request
+	now = now.Add(time.Minute + 45*time.Second)
+	for i := 0; i < 3; i++ {
+		res, _ = ms.Take(context.Background(), "key", limit)
+		if !res.Allowed {
+			t.Fatalf("request %d: expected to be allowed, got %+v", i, res)
+		}
+	}
+	res, _ = ms.Take(context.Background(), "key", limit)
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 15*time.Second {
+		t.Fatalf("expected to be limited with retry after the window, got %+v", res)
+	}
+
+	// after 2 windows, all the previous requests are expired
+	now = now.Add(2 * time.Minute)
+	res, _ = ms.Take(context.Background(), "key", limit)
+	if !res.Allowed || res.Remaining != 3 {
+		t.Fatalf("expected to be allowed, got %+v", res)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	ms := newTestStore(&now)
+	limit := Limit{Requests: 1, Window: time.Second}
+
+	_, _ = ms.Take(context.Background(), "a", limit)
+	now = now.Add(2 * time.Minute)
+	_, _ = ms.Take(context.Background(), "b", limit)
+	if _, ok := ms.buckets["a"]; ok || len(ms.buckets) != 1 {
+		t.Errorf("expected the expired bucket to be removed, got %d buckets", len(ms.buckets))
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		webgo.R200(w, "hello")
+	}
+	router := webgo.NewRouter(&webgo.Config{},
+		&webgo.Route{
+			Name:     "default",
+			Method:   http.MethodGet,
+			Pattern:  "/default",
+			Handlers: []http.HandlerFunc{handler},
+		},
+		&webgo.Route{
+			Name:    "login",
+			Method:  http.MethodGet,
+			Pattern: "/login",
+			Meta: map[string]interface{}{
+				MetaKey: Limit{Requests: 1, Window: time.Minute},
+			},
+			Handlers: []http.HandlerFunc{handler},
+		},
+	)
+	router.Use(RateLimit(&Config{
+		Limit: Limit{Requests: 2, Window: time.Minute, Algorithm: SlidingWindow},
+		Key:   ByAPIKey("Authorization"),
+	}))
+	router.SetupMiddleware()
+
+	request := func(path string, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		path          string
+		apiKey        string
+		wantStatus    int
+		wantRemaining string
+	}{
+		{path: "/default", apiKey: "a", wantStatus: http.StatusOK, wantRemaining: "1"},
+		{path: "/default", apiKey: "a", wantStatus: http.StatusOK, wantRemaining: "0"},
+		{path: "/default", apiKey: "a", wantStatus: http.StatusTooManyRequests, wantRemaining: "0"},
+		{path: "/default", apiKey: "b", wantStatus: http.StatusOK, wantRemaining: "1"},
+		// routes with their own limit are counted separately
+		{path: "/login", apiKey: "a", wantStatus: http.StatusOK, wantRemaining: "0"},
+		{path: "/login", apiKey: "a", wantStatus: http.StatusTooManyRequests, wantRemaining: "0"},
+	}
+	for i, tt := range tests {
+		w := request(tt.path, tt.apiKey)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%d: expected status '%d', got '%d'", i, tt.wantStatus, w.Code)
+		}
+		if got := w.Header().Get(headerRemaining); got != tt.wantRemaining {
+			t.Errorf("%d: expected remaining '%s', got '%s'", i, tt.wantRemaining, got)
+		}
+		if tt.wantStatus == http.StatusTooManyRequests && w.Header().Get(headerRetryAfter) == "" {
+			t.Errorf("%d: expected Retry-After header", i)
+		}
+	}
+
+	w := request("/login", "a")
+	if w.Header().Get(headerPolicy) != "1;w=60" {
+		t.Errorf("expected policy '1;w=60', got '%s'", w.Header().Get(headerPolicy))
+	}
+}
@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the result of a request being counted against a limit
+type Result struct {
+	// Allowed is true if the request is within the limit
+	Allowed bool
+	// Remaining is the number of requests remaining, within the current window
+	Remaining int
+	// Reset is the duration after which the limit is fully available again
+	Reset time.Duration
+	// RetryAfter is the duration after which the next request would be allowed, if the
+	// current one is not
+	RetryAfter time.Duration
+}
+
+// Store keeps track of the requests of all the keys. The default MemoryStore can be replaced by
+// a shared store (e.g. Redis), so that the limits are applied across multiple instances of the app
+type Store interface {
+	// Take counts a request for key against the limit, and returns the result
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state of a key, for both the algorithms
+type bucket struct {
+	// tokens & last are used by TokenBucket
+	tokens float64
+	last   time.Time
+
+	// windowStart, count & prevCount are used by SlidingWindow
+	windowStart time.Time
+	count       int
+	prevCount   int
+
+	expiry time.Time
+}
+
+// MemoryStore is an in-memory Store, it's applicable only for a single instance of the app
+type MemoryStore struct {
+	locker    sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	// sweepInterval is the interval at which expired buckets are removed
+	sweepInterval time.Duration
+	now           func() time.Time
+}
+
+// NewMemoryStore returns a new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:       make(map[string]*bucket),
+		sweepInterval: time.Minute,
+		now:           time.Now,
+	}
+}
+
+// Take implements the Store interface
+func (ms *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := ms.now()
+
+	ms.locker.Lock()
+	defer ms.locker.Unlock()
+
+	ms.sweep(now)
+
+	b, ok := ms.buckets[key]
+	if !ok {
+		b = &bucket{}
+		ms.buckets[key] = b
+	}
+
+	if limit.Algorithm == SlidingWindow {
+		return b.slidingWindow(now, limit), nil
+	}
+	return b.tokenBucket(now, limit), nil
+}
+
+// sweep removes the expired buckets, at most once every sweepInterval
+func (ms *MemoryStore) sweep(now time.Time) {
+	if now.Sub(ms.lastSweep) < ms.sweepInterval {
+		return
+	}
+	ms.lastSweep = now
+	for key, b := range ms.buckets {
+		if now.After(b.expiry) {
+			delete(ms.buckets, key)
+		}
+	}
+}
+
+func durationOf(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// tokenBucket refills the bucket at the rate of Requests per Window, up to Burst tokens.
+// Every request takes a token
+func (b *bucket) tokenBucket(now time.Time, limit Limit) Result {
+	capacity := float64(limit.burst())
+	rate := float64(limit.Requests) / limit.Window.Seconds()
+
+	if b.last.IsZero() {
+		b.tokens = capacity
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+
+	res := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = durationOf((1 - b.tokens) / rate)
+	}
+
+	res.Remaining = int(math.Floor(b.tokens))
+	res.Reset = durationOf((capacity - b.tokens) / rate)
+	b.expiry = now.Add(res.Reset)
+	return res
+}
+
+// slidingWindow approximates the number of requests in the last Window, using the count of the
+// current & previous fixed windows. The previous window's count is weighted by its overlap with
+// the sliding window
+func (b *bucket) slidingWindow(now time.Time, limit Limit) Result {
+	window := limit.Window
+	current := now.Truncate(window)
+	switch {
+	case b.windowStart.Equal(current):
+	case b.windowStart.Add(window).Equal(current):
+		b.prevCount, b.count = b.count, 0
+	default:
+		b.prevCount, b.count = 0, 0
+	}
+	b.windowStart = current
+
+	elapsed := now.Sub(current).Seconds() / window.Seconds()
+	estimate := float64(b.prevCount)*(1-elapsed) + float64(b.count)
+
+	res := Result{Reset: current.Add(window).Sub(now)}
+	if estimate+1 <= float64(limit.Requests) {
+		b.count++
+		estimate++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = res.Reset
+		// the weight of the previous window reduces with time, so a request could be allowed
+		// before the current window ends
+		if b.prevCount > 0 {
+			fraction := 1 - (float64(limit.Requests-1-b.count) / float64(b.prevCount))
+			if fraction > elapsed && fraction < 1 {
+				res.RetryAfter = durationOf((fraction - elapsed) * window.Seconds())
+			}
+		}
+	}
+
+	res.Remaining = int(math.Max(0, math.Floor(float64(limit.Requests)-estimate)))
+	b.expiry = current.Add(2 * window)
+	return res
+}
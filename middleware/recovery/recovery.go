@@ -0,0 +1,97 @@
+/*
+Package recovery provides a middleware which recovers from panics in the handlers. The panic is set
+as the error in the webgo context (with the stack trace), and the request is responded with 500
+using the router's ErrorHandler, unless a response was already sent to the client.
+*/
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+// ErrPanic is wrapped by all the PanicErrors, so that they can be identified using errors.Is
+var ErrPanic = errors.New("panic")
+
+// PanicError is the error set in the webgo context when a handler panics
+type PanicError struct {
+	// Value is the value the handler panicked with
+	Value interface{}
+	// Stack is the stack trace of the goroutine which panicked
+	Stack []byte
+}
+
+func (pe *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrPanic.Error(), pe.Value)
+}
+
+// Unwrap returns the value panicked with if it's an error, so that it can be checked using
+// errors.Is & errors.As
+func (pe *PanicError) Unwrap() error {
+	err, _ := pe.Value.(error)
+	return err
+}
+
+// Is returns true if target is ErrPanic
+func (pe *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// Reporter is called with every panic recovered, e.g. to report it to an error tracker
+type Reporter func(r *http.Request, perr *PanicError)
+
+// Config has the configurations of the recovery middleware
+type Config struct {
+	// Reporter is called with every panic recovered, it is called before responding to the
+	// client. Default is to log the panic along with the stack trace, using webgo.RequestLogger
+	Reporter Reporter
+}
+
+func defaultReporter(r *http.Request, perr *PanicError) {
+	webgo.RequestLogger(r).Error(
+		fmt.Sprintf("%s %s %s\n%s", r.Method, r.URL.String(), perr.Error(), perr.Stack),
+	)
+}
+
+// Recovery returns a middleware which recovers from panics in the following middleware &
+// handlers. It should be the last middleware added using router.Use, so that it is executed first
+// and recovers from panics in the rest of the middleware as well. http.ErrAbortHandler is not
+// recovered, since it is used to abort the response deliberately
+func Recovery(cfg *Config) webgo.Middleware {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	report := cfg.Reporter
+	if report == nil {
+		report = defaultReporter
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler { //nolint
+				panic(p)
+			}
+
+			perr := &PanicError{Value: p, Stack: debug.Stack()}
+			report(req, perr)
+
+			// a buffered response is discarded, so that the error can be responded cleanly
+			rd, _ := webgo.ResponseInfo(rw)
+			if rd.HeaderWritten && !webgo.DiscardResponse(rw) {
+				webgo.SetError(req, perr)
+				return
+			}
+			webgo.HandleError(rw, req, perr)
+		}()
+
+		next(rw, req)
+	}
+}
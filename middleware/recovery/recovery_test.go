@@ -0,0 +1,122 @@
+package recovery
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+var errHandler = errors.New("handler failed")
+
+func TestRecovery(t *testing.T) {
+	var (
+		reported *PanicError
+		ctxErr   error
+	)
+	router := setup()
+	router.Use(
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(w, r)
+			ctxErr = webgo.GetError(r)
+		},
+		Recovery(&Config{
+			Reporter: func(r *http.Request, perr *PanicError) {
+				reported = perr
+			},
+		}),
+	)
+	router.SetupMiddleware()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantErr    error
+	}{
+		{name: "no panic", path: "/hello", wantStatus: http.StatusOK, wantBody: "hello"},
+		{name: "panic", path: "/panic", wantStatus: http.StatusInternalServerError, wantBody: webgo.ErrInternalServer, wantErr: ErrPanic},
+		{name: "panic with error", path: "/panic-error", wantStatus: http.StatusInternalServerError, wantErr: errHandler},
+		{name: "buffered", path: "/buffered", wantStatus: http.StatusInternalServerError, wantBody: webgo.ErrInternalServer, wantErr: ErrPanic},
+		{name: "already written", path: "/written", wantStatus: http.StatusAccepted, wantBody: "partial", wantErr: ErrPanic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported, ctxErr = nil, nil
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d'", tt.wantStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain '%s', got '%s'", tt.wantBody, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "boom") {
+				t.Errorf("expected panic value to not be exposed, got '%s'", w.Body.String())
+			}
+
+			if tt.wantErr == nil {
+				if reported != nil || ctxErr != nil {
+					t.Errorf("expected no panic, got %v, %v", reported, ctxErr)
+				}
+				return
+			}
+			if reported == nil || len(reported.Stack) == 0 {
+				t.Fatal("expected the panic to be reported with the stack trace")
+			}
+			if !errors.Is(ctxErr, tt.wantErr) {
+				t.Errorf("expected error in context to be '%v', got '%v'", tt.wantErr, ctxErr)
+			}
+		})
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	mw := Recovery(nil)
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler { //nolint
+			t.Errorf("expected http.ErrAbortHandler to be re-panicked, got %v", p)
+		}
+	}()
+	mw(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+}
+
+func setup() *webgo.Router {
+	route := func(name string, bufferSize int64, h http.HandlerFunc) *webgo.Route {
+		return &webgo.Route{
+			Name:       name,
+			Pattern:    "/" + name,
+			Method:     http.MethodGet,
+			BufferSize: bufferSize,
+			Handlers:   []http.HandlerFunc{h},
+		}
+	}
+
+	return webgo.NewRouter(&webgo.Config{},
+		route("hello", 0, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`hello`))
+		}),
+		route("panic", 0, func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}),
+		route("panic-error", 0, func(w http.ResponseWriter, r *http.Request) {
+			panic(errHandler)
+		}),
+		route("buffered", 1024, func(w http.ResponseWriter, r *http.Request) {
+			webgo.Send(w, "text/plain", "partial", http.StatusAccepted)
+			panic("boom")
+		}),
+		route("written", 0, func(w http.ResponseWriter, r *http.Request) {
+			webgo.Send(w, "text/plain", "partial", http.StatusAccepted)
+			panic("boom")
+		}),
+	)
+}
@@ -0,0 +1,146 @@
+/*
+Package requestid provides a middleware which sets an ID for every request, to trace a request
+across services. The ID is read from the request header (default X-Request-ID) if it's valid,
+otherwise a new one is generated. The ID is echoed in the response header, is available using Get,
+and is added to the logs of webgo.RequestLogger (including the accesslog middleware).
+*/
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	// HeaderRequestID is the default header with the request ID
+	HeaderRequestID = "X-Request-ID"
+
+	// maxLength is the maximum length of a valid inbound request ID
+	maxLength = 128
+	// crockford is the Base32 alphabet used by ULIDs
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+type ctxkey string
+
+const requestIDKey = ctxkey("requestid")
+
+// Generator generates a new request ID
+type Generator func() string
+
+// Config has the configurations of the request ID middleware
+type Config struct {
+	// Header is the request & response header with the request ID, default is X-Request-ID
+	Header string
+	// Generator generates the ID for a request without a valid ID, default is UUIDv4
+	Generator Generator
+	// Validator validates the ID in the request header. Invalid IDs are replaced with a new one.
+	// Default allows IDs of up to 128 characters with letters, digits and `-_.:+/=@`
+	Validator func(id string) bool
+	// IgnoreInbound if true, the ID in the request header is ignored and a new one is always
+	// generated. e.g. for services exposed to the internet
+	IgnoreInbound bool
+}
+
+// UUIDv4 returns a random UUID (version 4), e.g. "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+func UUIDv4() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	// version 4 & variant 10 (RFC 4122)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+// ULID returns a lexicographically sortable ID (https://github.com/ulid/spec), with the current
+// time in milliseconds & 80 random bits, e.g. "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+func ULID() string {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(time.Now().UnixMilli())<<16)
+	_, _ = rand.Read(b[6:])
+
+	// the 128 bits are encoded as 26 characters of 5 bits each, most significant bits first
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+func validID(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=', c == '@':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the ID of the request, set by the middleware
+func Get(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey).(string); ok {
+		return id
+	}
+	return webgo.RequestID(r)
+}
+
+// RequestID returns the request ID middleware
+func RequestID(cfg *Config) webgo.Middleware {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	header := cfg.Header
+	if header == "" {
+		header = HeaderRequestID
+	}
+	generate := cfg.Generator
+	if generate == nil {
+		generate = UUIDv4
+	}
+	valid := cfg.Validator
+	if valid == nil {
+		valid = validID
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		id := ""
+		if !cfg.IgnoreInbound {
+			id = req.Header.Get(header)
+		}
+		if !valid(id) {
+			id = generate()
+		}
+
+		rw.Header().Set(header, id)
+		webgo.SetRequestID(req, id)
+		next(rw, req.WithContext(context.WithValue(req.Context(), requestIDKey, id)))
+	}
+}
@@ -0,0 +1,111 @@
+package requestid
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bnkamalesh/webgo/v7"
+	"github.com/bnkamalesh/webgo/v7/middleware/accesslog"
+)
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidRegex = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func TestGenerators(t *testing.T) {
+	if id := UUIDv4(); !uuidRegex.MatchString(id) {
+		t.Errorf("invalid UUIDv4 '%s'", id)
+	}
+
+	first := ULID()
+	if !ulidRegex.MatchString(first) {
+		t.Errorf("invalid ULID '%s'", first)
+	}
+	if second := ULID(); second[:10] < first[:10] {
+		t.Errorf("expected ULIDs to be sortable by time, got '%s' after '%s'", second, first)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	stdout := bytes.NewBuffer(nil)
+	webgo.GlobalLoggerConfig(stdout, nil)
+	defer webgo.GlobalLoggerConfig(nil, nil)
+
+	var gotID string
+	router := webgo.NewRouter(&webgo.Config{}, &webgo.Route{
+		Name:    "hello",
+		Pattern: "/hello",
+		Method:  http.MethodGet,
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				gotID = Get(r)
+				_, _ = w.Write([]byte(`hello`))
+			},
+		},
+	})
+	router.Use(accesslog.AccessLog, RequestID(nil))
+	router.SetupMiddleware()
+
+	tests := []struct {
+		name     string
+		inbound  string
+		wantSame bool
+	}{
+		{name: "generated", inbound: ""},
+		{name: "inbound", inbound: "abc-123", wantSame: true},
+		{name: "invalid inbound", inbound: "abc 123\n"},
+		{name: "too long", inbound: strings.Repeat("a", 200)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout.Reset()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+			if tt.inbound != "" {
+				req.Header.Set(HeaderRequestID, tt.inbound)
+			}
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(HeaderRequestID)
+			if tt.wantSame && id != tt.inbound {
+				t.Errorf("expected ID '%s', got '%s'", tt.inbound, id)
+			}
+			if !tt.wantSame && !uuidRegex.MatchString(id) {
+				t.Errorf("expected a generated UUID, got '%s'", id)
+			}
+			if gotID != id {
+				t.Errorf("expected Get to return '%s', got '%s'", id, gotID)
+			}
+			if !strings.Contains(stdout.String(), "request_id="+id) {
+				t.Errorf("expected access log to have the request ID, got '%s'", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRequestIDConfig(t *testing.T) {
+	mw := RequestID(&Config{
+		Header:        "X-Correlation-ID",
+		Generator:     ULID,
+		IgnoreInbound: true,
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", "abc")
+	var gotID string
+	mw(w, req, func(w http.ResponseWriter, r *http.Request) {
+		gotID = Get(r)
+	})
+
+	id := w.Header().Get("X-Correlation-ID")
+	if !ulidRegex.MatchString(id) || gotID != id {
+		t.Errorf("expected a generated ULID, got '%s', '%s'", id, gotID)
+	}
+}
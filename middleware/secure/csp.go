@@ -0,0 +1,102 @@
+package secure
+
+import (
+	"strings"
+)
+
+// Sources of the Content-Security-Policy directives
+const (
+	Self          = "'self'"
+	None          = "'none'"
+	UnsafeInline  = "'unsafe-inline'"
+	UnsafeEval    = "'unsafe-eval'"
+	StrictDynamic = "'strict-dynamic'"
+	// NonceSource is replaced with the nonce of the request, i.e. 'nonce-<nonce>'
+	NonceSource = "'nonce'"
+)
+
+// directive is a Content-Security-Policy directive with its sources
+type directive struct {
+	name    string
+	sources []string
+}
+
+// CSP builds a Content-Security-Policy. The directives are in the order they are added, and the
+// NonceSource is replaced with a new nonce for every request
+type CSP struct {
+	directives []directive
+	// ReportOnly if true, the policy is sent as Content-Security-Policy-Report-Only, i.e. the
+	// violations are only reported & not enforced
+	ReportOnly bool
+}
+
+// NewCSP returns an empty CSP
+func NewCSP() *CSP {
+	return &CSP{}
+}
+
+// DefaultCSP returns a strict CSP, which allows resources only from the same origin, and scripts &
+// styles with the nonce of the request
+func DefaultCSP() *CSP {
+	return NewCSP().
+		Add("default-src", Self).
+		Add("script-src", Self, NonceSource).
+		Add("style-src", Self, NonceSource).
+		Add("img-src", Self, "data:").
+		Add("object-src", None).
+		Add("base-uri", Self).
+		Add("form-action", Self).
+		Add("frame-ancestors", None)
+}
+
+// Add adds the sources to the directive, e.g. Add("script-src", secure.Self, "https://cdn.example.com").
+// Adding an existing directive appends the sources to it
+func (csp *CSP) Add(name string, sources ...string) *CSP {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range csp.directives {
+		if csp.directives[i].name == name {
+			csp.directives[i].sources = append(csp.directives[i].sources, sources...)
+			return csp
+		}
+	}
+	csp.directives = append(csp.directives, directive{name: name, sources: sources})
+	return csp
+}
+
+// usesNonce returns true if any of the directives has the NonceSource
+func (csp *CSP) usesNonce() bool {
+	for _, d := range csp.directives {
+		for _, src := range d.sources {
+			if src == NonceSource {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// header returns the name of the response header
+func (csp *CSP) header() string {
+	if csp.ReportOnly {
+		return headerCSPReportOnly
+	}
+	return headerCSP
+}
+
+// String returns the policy, with the NonceSource as is
+func (csp *CSP) String() string {
+	parts := make([]string, 0, len(csp.directives))
+	for _, d := range csp.directives {
+		if len(d.sources) == 0 {
+			parts = append(parts, d.name)
+			continue
+		}
+		parts = append(parts, d.name+" "+strings.Join(d.sources, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// withNonce returns the policy with the NonceSource replaced by the nonce
+func withNonce(policy string, nonce string) string {
+	return strings.ReplaceAll(policy, NonceSource, "'nonce-"+nonce+"'")
+}
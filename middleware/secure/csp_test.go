@@ -0,0 +1,36 @@
+package secure
+
+import (
+	"testing"
+)
+
+func TestCSP(t *testing.T) {
+	csp := NewCSP().
+		Add("default-src", Self).
+		Add("Script-Src", Self, NonceSource).
+		Add("script-src", "https://cdn.example.com").
+		Add("upgrade-insecure-requests")
+
+	want := "default-src 'self'; script-src 'self' 'nonce' https://cdn.example.com; upgrade-insecure-requests"
+	if got := csp.String(); got != want {
+		t.Errorf("expected '%s', got '%s'", want, got)
+	}
+	if !csp.usesNonce() {
+		t.Error("expected the policy to use a nonce")
+	}
+	if got := withNonce(csp.String(), "abc"); got != "default-src 'self'; script-src 'self' 'nonce-abc' https://cdn.example.com; upgrade-insecure-requests" {
+		t.Errorf("unexpected policy with nonce '%s'", got)
+	}
+
+	if csp.header() != headerCSP {
+		t.Errorf("expected header '%s', got '%s'", headerCSP, csp.header())
+	}
+	csp.ReportOnly = true
+	if csp.header() != headerCSPReportOnly {
+		t.Errorf("expected header '%s', got '%s'", headerCSPReportOnly, csp.header())
+	}
+
+	if NewCSP().Add("default-src", Self).usesNonce() {
+		t.Error("expected the policy to not use a nonce")
+	}
+}
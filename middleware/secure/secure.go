@@ -0,0 +1,244 @@
+/*
+Package secure provides a middleware which sets the security headers of the responses, i.e.
+Strict-Transport-Security, X-Content-Type-Options, X-Frame-Options, Referrer-Policy,
+Permissions-Policy, Cross-Origin-*-Policy & Content-Security-Policy. All of them have secure
+defaults, except the Content-Security-Policy & Cross-Origin-Embedder-Policy, which depend on the
+resources used by the app.
+
+The Content-Security-Policy can have a nonce, which is generated for every request. It's available
+using Nonce, and to the templates as {{csp_nonce}}, e.g. <script nonce="{{csp_nonce}}">. Templates
+rendered using webgo.Render should declare the function when parsed, refer TemplateFuncs.
+*/
+package secure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	// Omit is the value of a header in Config, to not set the header
+	Omit = "-"
+
+	headerHSTS               = "Strict-Transport-Security"
+	headerContentTypeOptions = "X-Content-Type-Options"
+	headerFrameOptions       = "X-Frame-Options"
+	headerReferrerPolicy     = "Referrer-Policy"
+	headerPermissionsPolicy  = "Permissions-Policy"
+	headerCOOP               = "Cross-Origin-Opener-Policy"
+	headerCOEP               = "Cross-Origin-Embedder-Policy"
+	headerCORP               = "Cross-Origin-Resource-Policy"
+	headerCSP                = "Content-Security-Policy"
+	headerCSPReportOnly      = "Content-Security-Policy-Report-Only"
+	headerXForwardedProto    = "X-Forwarded-Proto"
+
+	defaultContentTypeOptions = "nosniff"
+	defaultFrameOptions       = "DENY"
+	defaultReferrerPolicy     = "strict-origin-when-cross-origin"
+	defaultPermissionsPolicy  = "camera=(), microphone=(), geolocation=(), payment=(), usb=()"
+	defaultCrossOriginPolicy  = "same-origin"
+
+	defaultHSTSMaxAge = 2 * 365 * 24 * time.Hour
+	// minPreloadMaxAge is the minimum max-age required by the HSTS preload list
+	minPreloadMaxAge = 365 * 24 * time.Hour
+
+	nonceLength = 16
+)
+
+type ctxkey string
+
+const nonceKey = ctxkey("cspnonce")
+
+// TemplateFuncs declares the template functions provided by the middleware, they should be added
+// to the templates rendered using webgo.Render while parsing. e.g.
+// template.New("page").Funcs(secure.TemplateFuncs).Parse(...)
+var TemplateFuncs = template.FuncMap{
+	"csp_nonce": func() string {
+		return ""
+	},
+}
+
+// HSTSConfig has the configurations of Strict-Transport-Security
+type HSTSConfig struct {
+	// Disabled if true, the header is not set
+	Disabled bool
+	// MaxAge is the duration for which the browsers should only use HTTPS, default is 2 years
+	MaxAge time.Duration
+	// IncludeSubDomains if true, the policy applies to all the subdomains as well
+	IncludeSubDomains bool
+	// Preload if true, the domain can be included in the HSTS preload list of browsers. It
+	// requires IncludeSubDomains & a MaxAge of at least 1 year, which are enforced
+	Preload bool
+}
+
+// value returns the value of the header
+func (hc *HSTSConfig) value() string {
+	maxAge := hc.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultHSTSMaxAge
+	}
+	subdomains := hc.IncludeSubDomains
+	if hc.Preload {
+		subdomains = true
+		if maxAge < minPreloadMaxAge {
+			maxAge = minPreloadMaxAge
+		}
+	}
+
+	value := fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))
+	if subdomains {
+		value += "; includeSubDomains"
+	}
+	if hc.Preload {
+		value += "; preload"
+	}
+	return value
+}
+
+// Config has the configurations of the security headers middleware. The value of a header can be
+// set to Omit, to not set the header
+type Config struct {
+	// HSTS is set only for HTTPS requests, as browsers ignore it otherwise
+	HSTS HSTSConfig
+	// TrustForwardedProto if true, requests with X-Forwarded-Proto: https are considered HTTPS.
+	// It should be enabled only if the app is behind a proxy which terminates TLS
+	TrustForwardedProto bool
+
+	// ContentTypeOptions is the value of X-Content-Type-Options, default is "nosniff"
+	ContentTypeOptions string
+	// FrameOptions is the value of X-Frame-Options, default is "DENY"
+	FrameOptions string
+	// ReferrerPolicy is the value of Referrer-Policy, default is "strict-origin-when-cross-origin"
+	ReferrerPolicy string
+	// PermissionsPolicy is the value of Permissions-Policy, default disables camera, microphone,
+	// geolocation, payment & usb
+	PermissionsPolicy string
+	// CrossOriginOpenerPolicy is the value of Cross-Origin-Opener-Policy, default is "same-origin"
+	CrossOriginOpenerPolicy string
+	// CrossOriginEmbedderPolicy is the value of Cross-Origin-Embedder-Policy, e.g. "require-corp".
+	// It's not set by default
+	CrossOriginEmbedderPolicy string
+	// CrossOriginResourcePolicy is the value of Cross-Origin-Resource-Policy, default is "same-origin"
+	CrossOriginResourcePolicy string
+
+	// CSP is the Content-Security-Policy, it's not set if nil. Refer DefaultCSP
+	CSP *CSP
+}
+
+// header is a response header, set by the middleware
+type header struct {
+	name  string
+	value string
+}
+
+func (c *Config) headers() []header {
+	all := []header{
+		{name: headerContentTypeOptions, value: withDefault(c.ContentTypeOptions, defaultContentTypeOptions)},
+		{name: headerFrameOptions, value: withDefault(c.FrameOptions, defaultFrameOptions)},
+		{name: headerReferrerPolicy, value: withDefault(c.ReferrerPolicy, defaultReferrerPolicy)},
+		{name: headerPermissionsPolicy, value: withDefault(c.PermissionsPolicy, defaultPermissionsPolicy)},
+		{name: headerCOOP, value: withDefault(c.CrossOriginOpenerPolicy, defaultCrossOriginPolicy)},
+		{name: headerCOEP, value: withDefault(c.CrossOriginEmbedderPolicy, Omit)},
+		{name: headerCORP, value: withDefault(c.CrossOriginResourcePolicy, defaultCrossOriginPolicy)},
+	}
+
+	headers := make([]header, 0, len(all))
+	for _, h := range all {
+		if h.value != Omit {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+func withDefault(value string, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// Nonce returns the nonce of the Content-Security-Policy of the request
+func Nonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(nonceKey).(string)
+	return nonce
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, nonceLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func isHTTPS(r *http.Request, trustForwardedProto bool) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return trustForwardedProto && strings.EqualFold(r.Header.Get(headerXForwardedProto), "https")
+}
+
+// Secure returns the security headers middleware. The headers are set before calling next, so
+// that the handlers can override them if required
+func Secure(cfg *Config) webgo.Middleware {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	headers := c.headers()
+	hsts := c.HSTS.value()
+
+	var (
+		policy    string
+		cspHeader string
+		nonce     bool
+	)
+	if c.CSP != nil {
+		policy, cspHeader, nonce = c.CSP.String(), c.CSP.header(), c.CSP.usesNonce()
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		header := rw.Header()
+		for _, h := range headers {
+			header.Set(h.name, h.value)
+		}
+		if !c.HSTS.Disabled && isHTTPS(req, c.TrustForwardedProto) {
+			header.Set(headerHSTS, hsts)
+		}
+
+		if policy == "" {
+			next(rw, req)
+			return
+		}
+		if !nonce {
+			header.Set(cspHeader, policy)
+			next(rw, req)
+			return
+		}
+
+		value, err := newNonce()
+		if err != nil {
+			webgo.HandleError(rw, req, err)
+			return
+		}
+		header.Set(cspHeader, withNonce(policy, value))
+		req = req.WithContext(context.WithValue(req.Context(), nonceKey, value))
+		webgo.AddTemplateFuncs(req, template.FuncMap{
+			"csp_nonce": func() string {
+				return value
+			},
+		})
+
+		next(rw, req)
+	}
+}
@@ -0,0 +1,148 @@
+package secure
+
+import (
+	"crypto/tls"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+func TestSecure(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         *Config
+		tls         bool
+		forwarded   bool
+		wantHeaders map[string]string
+	}{
+		{
+			name: "defaults",
+			wantHeaders: map[string]string{
+				headerContentTypeOptions: "nosniff",
+				headerFrameOptions:       "DENY",
+				headerReferrerPolicy:     defaultReferrerPolicy,
+				headerPermissionsPolicy:  defaultPermissionsPolicy,
+				headerCOOP:               "same-origin",
+				headerCORP:               "same-origin",
+				headerCOEP:               "",
+				headerHSTS:               "",
+				headerCSP:                "",
+			},
+		},
+		{
+			name: "https",
+			tls:  true,
+			wantHeaders: map[string]string{
+				headerHSTS: "max-age=63072000",
+			},
+		},
+		{
+			name: "preload",
+			cfg:  &Config{HSTS: HSTSConfig{MaxAge: time.Hour, Preload: true}},
+			tls:  true,
+			wantHeaders: map[string]string{
+				headerHSTS: "max-age=31536000; includeSubDomains; preload",
+			},
+		},
+		{
+			name:      "forwarded proto",
+			cfg:       &Config{TrustForwardedProto: true, HSTS: HSTSConfig{IncludeSubDomains: true}},
+			forwarded: true,
+			wantHeaders: map[string]string{
+				headerHSTS: "max-age=63072000; includeSubDomains",
+			},
+		},
+		{
+			name:      "untrusted forwarded proto",
+			forwarded: true,
+			wantHeaders: map[string]string{
+				headerHSTS: "",
+			},
+		},
+		{
+			name: "overrides",
+			cfg: &Config{
+				HSTS:                      HSTSConfig{Disabled: true},
+				FrameOptions:              "SAMEORIGIN",
+				ContentTypeOptions:        Omit,
+				CrossOriginEmbedderPolicy: "require-corp",
+				CSP:                       NewCSP().Add("default-src", Self),
+			},
+			tls: true,
+			wantHeaders: map[string]string{
+				headerHSTS:               "",
+				headerFrameOptions:       "SAMEORIGIN",
+				headerContentTypeOptions: "",
+				headerCOEP:               "require-corp",
+				headerCSP:                "default-src 'self'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.forwarded {
+				req.Header.Set(headerXForwardedProto, "https")
+			}
+			w := httptest.NewRecorder()
+			Secure(tt.cfg)(w, req, func(w http.ResponseWriter, r *http.Request) {
+				if Nonce(r) != "" {
+					t.Error("expected no nonce")
+				}
+			})
+
+			for name, want := range tt.wantHeaders {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("expected %s '%s', got '%s'", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSecureNonce(t *testing.T) {
+	tpl, err := template.New("page").Funcs(TemplateFuncs).Parse(`<script nonce="{{csp_nonce}}"></script>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := webgo.NewRouter(&webgo.Config{}, &webgo.Route{
+		Name:    "page",
+		Method:  http.MethodGet,
+		Pattern: "/page",
+		Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+			webgo.Render(w, nil, http.StatusOK, tpl)
+		}},
+	})
+	router.Use(Secure(&Config{CSP: DefaultCSP()}))
+	router.SetupMiddleware()
+
+	// rendering without the middleware first, e.g. the not found page
+	webgo.Render(httptest.NewRecorder(), nil, http.StatusNotFound, tpl)
+
+	nonces := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+
+		policy := w.Header().Get(headerCSP)
+		body := w.Body.String()
+		start := strings.Index(body, `nonce="`) + len(`nonce="`)
+		nonce := body[start : start+strings.Index(body[start:], `"`)]
+		if nonce == "" || !strings.Contains(policy, "script-src 'self' 'nonce-"+nonce+"'") {
+			t.Fatalf("expected the nonce '%s' in the policy '%s'", nonce, policy)
+		}
+		nonces[nonce] = true
+	}
+	if len(nonces) != 2 {
+		t.Error("expected a new nonce for every request")
+	}
+}
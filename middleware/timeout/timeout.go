@@ -0,0 +1,288 @@
+/*
+Package timeout provides a middleware which limits the duration for the handlers to respond. The
+request context has a deadline, and if the handlers have not written the response by then, the
+request is responded with 503 (or 504) using the router's ErrorHandler. Any writes by the handlers
+after the deadline are discarded.
+
+The timeout can be configured per route using Route.Timeout. The server's write deadline is
+extended to match the timeout of the route, and routes with a negative timeout (e.g. streams like
+Server-Sent Events) have no timeout & no write deadline. So the server's WriteTimeout need not be
+set to the duration of the longest stream.
+*/
+package timeout
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+const (
+	headerContentLength = "Content-Length"
+
+	// defaultWriteTimeout is the default duration allowed for writing the response, after the timeout
+	defaultWriteTimeout = 5 * time.Second
+)
+
+// ErrTimeout is responded when the handlers do not respond before the timeout
+var ErrTimeout = webgo.NewHTTPError(http.StatusServiceUnavailable, "timeout", "")
+
+// Config has the configurations of the timeout middleware
+type Config struct {
+	// Timeout is the default timeout, for routes without Route.Timeout. If it's zero, only the
+	// routes with a timeout are limited
+	Timeout time.Duration
+	// Status is the status code responded on timeout, default is 503. e.g. 504 for a gateway
+	Status int
+	// WriteTimeout is the duration allowed for writing the response after the timeout, the write
+	// deadline of the connection is set to Timeout + WriteTimeout. Default is 5 seconds
+	WriteTimeout time.Duration
+}
+
+// routeTimeout returns the timeout of the route, if available
+func routeTimeout(r *http.Request) (time.Duration, bool) {
+	cp := webgo.Context(r)
+	if cp == nil || cp.Route == nil || cp.Route.Timeout == 0 {
+		return 0, false
+	}
+	return cp.Route.Timeout, true
+}
+
+// timeoutWriter writes the response of the handlers, till the timeout. The handlers have their
+// own header, so that they can still access it after the timeout, while the response is written
+type timeoutWriter struct {
+	rw     http.ResponseWriter
+	header http.Header
+	// ctx is the context with the deadline, writes after the deadline are discarded even before
+	// the timeout response is written
+	ctx context.Context
+
+	locker      sync.Mutex
+	timedOut    bool
+	wroteHeader bool
+	// completed is true if the handlers returned before the deadline
+	completed bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// expired returns true once the deadline is exceeded
+func (tw *timeoutWriter) expired() bool {
+	return tw.timedOut || tw.ctx.Err() == context.DeadlineExceeded
+}
+
+// copyHeader replaces the header of the underlying response writer with the handlers' header
+func (tw *timeoutWriter) copyHeader() {
+	dst := tw.rw.Header()
+	for key := range dst {
+		delete(dst, key)
+	}
+	for key, values := range tw.header {
+		dst[key] = append([]string(nil), values...)
+	}
+}
+
+func (tw *timeoutWriter) writeHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.copyHeader()
+	tw.rw.WriteHeader(code)
+}
+
+// complete is called once the handlers return. If it's before the deadline, the header is retained
+// even if the handlers have not written the response
+func (tw *timeoutWriter) complete() {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if tw.expired() {
+		return
+	}
+	tw.completed = true
+	if !tw.wroteHeader {
+		tw.copyHeader()
+	}
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if tw.expired() {
+		return
+	}
+	tw.writeHeader(code)
+}
+
+// Write writes to the response, it returns http.ErrHandlerTimeout after the timeout
+func (tw *timeoutWriter) Write(body []byte) (int, error) {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if tw.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeader(http.StatusOK)
+	return tw.rw.Write(body)
+}
+
+// Flush implements the http.Flusher interface, it's a no-op after the timeout
+func (tw *timeoutWriter) Flush() {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if tw.expired() {
+		return
+	}
+	tw.writeHeader(http.StatusOK)
+	_ = http.NewResponseController(tw.rw).Flush()
+}
+
+// Unwrap returns the underlying response writer, so that webgo.ResponseInfo works with it
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.rw
+}
+
+// errorWriter holds the timeout response, so that it's written with Content-Length. Otherwise the
+// client would receive the complete response only after the handlers return
+type errorWriter struct {
+	rw     http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (ew *errorWriter) Header() http.Header {
+	return ew.rw.Header()
+}
+
+func (ew *errorWriter) WriteHeader(code int) {
+	if ew.status == 0 {
+		ew.status = code
+	}
+}
+
+func (ew *errorWriter) Write(body []byte) (int, error) {
+	ew.WriteHeader(http.StatusOK)
+	return ew.body.Write(body)
+}
+
+// Unwrap returns the underlying response writer, so that the router's configurations are
+// respected by the ErrorHandler
+func (ew *errorWriter) Unwrap() http.ResponseWriter {
+	return ew.rw
+}
+
+// respond writes the timeout response, unless the handlers returned before the deadline. If the
+// handlers have already written the response, it is left as is. The error is not set in the webgo
+// context, since the handlers may still be using it, refer Timeout
+func (tw *timeoutWriter) respond(req *http.Request, err *webgo.HTTPError) {
+	tw.locker.Lock()
+	defer tw.locker.Unlock()
+	if tw.completed {
+		return
+	}
+	tw.timedOut = true
+
+	if tw.wroteHeader {
+		return
+	}
+
+	ew := &errorWriter{rw: tw.rw}
+	webgo.RespondError(ew, req, err)
+	if ew.status == 0 {
+		ew.status = err.Status
+	}
+	tw.rw.Header().Set(headerContentLength, strconv.Itoa(ew.body.Len()))
+	tw.rw.WriteHeader(ew.status)
+	_, _ = tw.rw.Write(ew.body.Bytes())
+	_ = http.NewResponseController(tw.rw).Flush()
+}
+
+// Timeout returns the timeout middleware. The handlers are executed in a separate goroutine, and
+// are expected to return once the request context is done. On timeout, the response is sent to
+// the client right away, though the middleware returns only after the handlers return, because
+// the webgo context of the request is reused once the request is complete. Panics in the handlers
+// are propagated, so that they can be recovered by the recovery middleware
+func Timeout(cfg *Config) webgo.Middleware {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = defaultWriteTimeout
+	}
+	errTimeout := ErrTimeout
+	if c.Status != 0 && c.Status != ErrTimeout.Status {
+		errTimeout = webgo.NewHTTPError(c.Status, ErrTimeout.Code, "")
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		timeout := c.Timeout
+		if t, ok := routeTimeout(req); ok {
+			timeout = t
+		}
+
+		if timeout < 0 {
+			// errors are ignored, since not all response writers support deadlines
+			_ = http.NewResponseController(rw).SetWriteDeadline(time.Time{})
+			next(rw, req)
+			return
+		}
+		if timeout == 0 {
+			next(rw, req)
+			return
+		}
+
+		_ = http.NewResponseController(rw).SetWriteDeadline(time.Now().Add(timeout + c.WriteTimeout))
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{
+			rw:     rw,
+			header: rw.Header().Clone(),
+			ctx:    ctx,
+		}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+				tw.complete()
+				close(done)
+			}()
+			next(tw, req.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		var err *webgo.HTTPError
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errTimeout.WithCause(ctx.Err())
+			tw.respond(req, err)
+		}
+		<-done
+
+		// the error is set only after the handlers return, since they may set an error as well
+		tw.locker.Lock()
+		timedOut := tw.timedOut
+		tw.locker.Unlock()
+		if timedOut {
+			webgo.SetError(req, err)
+		}
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		default:
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bnkamalesh/webgo/v7"
+)
+
+func TestTimeout(t *testing.T) {
+	var (
+		lateWriteErr atomic.Value
+		returned     int32
+		handlerErr   error
+	)
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		_, err := w.Write([]byte("late"))
+		lateWriteErr.Store(err)
+		atomic.StoreInt32(&returned, 1)
+	}
+	router := webgo.NewRouter(&webgo.Config{},
+		&webgo.Route{
+			Name:    "fast",
+			Method:  http.MethodGet,
+			Pattern: "/fast",
+			Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := r.Context().Deadline(); !ok {
+					t.Error("expected the request context to have a deadline")
+				}
+				w.Header().Set("X-Handler", "true")
+				webgo.R200(w, "hello")
+			}},
+		},
+		&webgo.Route{
+			Name:     "slow",
+			Method:   http.MethodGet,
+			Pattern:  "/slow",
+			Timeout:  20 * time.Millisecond,
+			Handlers: []http.HandlerFunc{slow},
+		},
+		&webgo.Route{
+			Name:    "partial",
+			Method:  http.MethodGet,
+			Pattern: "/partial",
+			Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("partial"))
+				<-r.Context().Done()
+				_, _ = w.Write([]byte(" late"))
+			}},
+		},
+		&webgo.Route{
+			Name:    "stream",
+			Method:  http.MethodGet,
+			Pattern: "/stream",
+			Timeout: -1,
+			Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+				if _, ok := r.Context().Deadline(); ok {
+					t.Error("expected no deadline for the stream")
+				}
+				webgo.R200(w, "stream")
+			}},
+		},
+	)
+	router.Use(
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			w.Header().Set("X-Outer", "true")
+			next(w, r)
+			handlerErr = webgo.GetError(r)
+		},
+		Timeout(&Config{Timeout: 50 * time.Millisecond}),
+	)
+	router.SetupMiddleware()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantBody     string
+		wantErr      bool
+		wantReturned bool
+	}{
+		{name: "fast", path: "/fast", wantStatus: http.StatusOK, wantBody: "hello"},
+		{name: "slow", path: "/slow", wantStatus: http.StatusServiceUnavailable, wantBody: "timeout", wantErr: true, wantReturned: true},
+		{name: "partial", path: "/partial", wantStatus: http.StatusOK, wantBody: "partial", wantErr: true},
+		{name: "stream", path: "/stream", wantStatus: http.StatusOK, wantBody: "stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&returned, 0)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d'", tt.wantStatus, w.Code)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, tt.wantBody) || strings.Contains(body, "late") {
+				t.Errorf("expected body to contain '%s' without late writes, got '%s'", tt.wantBody, body)
+			}
+			if w.Header().Get("X-Outer") != "true" {
+				t.Error("expected the header set by the outer middleware to be retained")
+			}
+			if (handlerErr != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got '%v'", tt.wantErr, handlerErr)
+			}
+			if tt.wantErr && !errors.Is(handlerErr, ErrTimeout) {
+				t.Errorf("expected ErrTimeout, got '%v'", handlerErr)
+			}
+			if tt.wantReturned {
+				if atomic.LoadInt32(&returned) != 1 {
+					t.Error("expected the middleware to wait for the handler to return")
+				}
+				if err, _ := lateWriteErr.Load().(error); !errors.Is(err, http.ErrHandlerTimeout) {
+					t.Errorf("expected late write to fail with ErrHandlerTimeout, got '%v'", err)
+				}
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Header().Get(headerContentLength) != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("expected Content-Length '%d', got '%s'", w.Body.Len(), w.Header().Get(headerContentLength))
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Header().Get("X-Handler") != "true" {
+		t.Error("expected the header set by the handler")
+	}
+}
+
+func TestTimeoutStatus(t *testing.T) {
+	handler := Timeout(&Config{Timeout: 10 * time.Millisecond, Status: http.StatusGatewayTimeout})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil), func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected status '%d', got '%d'", http.StatusGatewayTimeout, w.Code)
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	handler := Timeout(&Config{Timeout: time.Second})
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("expected the panic to be propagated, got '%v'", p)
+		}
+	}()
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+}
+
+func TestTimeoutCanceled(t *testing.T) {
+	handler := Timeout(&Config{Timeout: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	if w.Body.Len() != 0 {
+		t.Errorf("expected no response when the request is canceled, got '%s'", w.Body.String())
+	}
+}
+
+func TestTimeoutHandlerError(t *testing.T) {
+	var handlerErr error
+	router := webgo.NewRouter(&webgo.Config{}, &webgo.Route{
+		Name:    "slow",
+		Method:  http.MethodGet,
+		Pattern: "/slow",
+		HandlersE: []webgo.HandlerFuncE{func(w http.ResponseWriter, r *http.Request) error {
+			<-r.Context().Done()
+			return r.Context().Err()
+		}},
+	})
+	router.Use(
+		func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			next(w, r)
+			handlerErr = webgo.GetError(r)
+		},
+		Timeout(&Config{Timeout: 10 * time.Millisecond}),
+	)
+	router.SetupMiddleware()
+
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "timeout") {
+			t.Errorf("expected the timeout response, got '%d': %s", w.Code, w.Body.String())
+		}
+		if !errors.Is(handlerErr, ErrTimeout) {
+			t.Errorf("expected ErrTimeout, got '%v'", handlerErr)
+		}
+	}
+}
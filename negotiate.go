@@ -0,0 +1,335 @@
+package webgo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HeaderAccept is the request header used by the client to list the acceptable media types
+	HeaderAccept = "Accept"
+	// HeaderVary is the response header listing the request headers used to choose the response
+	HeaderVary = "Vary"
+
+	// XMLContentType is the MIME type when the response is XML
+	XMLContentType = "application/xml; charset=UTF-8"
+	// CSVContentType is the MIME type when the response is CSV
+	CSVContentType = "text/csv; charset=UTF-8"
+	// TextContentType is the MIME type when the response is plain text
+	TextContentType = "text/plain; charset=UTF-8"
+)
+
+var (
+	// ErrUnsupportedCSV is returned by the CSV encoder, if the data cannot be represented as CSV
+	ErrUnsupportedCSV = errors.New("slices of non-struct values cannot be encoded as CSV")
+	// ErrJSONEncoderWriter is returned by the JSON encoder, if it's not writing to an
+	// http.ResponseWriter. It responds using the router's envelopes, refer Respond
+	ErrJSONEncoderWriter = errors.New("the JSON encoder can only write to an http.ResponseWriter")
+
+	// encoders is the list of all encoders available for content negotiation, in the order of preference
+	encoders = []registeredEncoder{
+		{mediaType: "application/json", encoder: jsonEncoder{}},
+		{mediaType: "application/xml", encoder: xmlEncoder{}},
+		{mediaType: "text/xml", encoder: xmlEncoder{}},
+		{mediaType: "text/csv", encoder: csvEncoder{}},
+		{mediaType: "text/plain", encoder: textEncoder{}},
+	}
+)
+
+// Encoder encodes the response payload for a specific media type
+type Encoder interface {
+	// ContentType is the value of the Content-Type response header
+	ContentType() string
+	// Encode writes data to w, rCode is the HTTP response status code
+	Encode(w io.Writer, data interface{}, rCode int) error
+}
+
+type registeredEncoder struct {
+	mediaType string
+	encoder   Encoder
+}
+
+// RegisterEncoder adds an encoder for the media type (e.g. "application/yaml"), which would be
+// used by Respond. If an encoder already exists for the media type, it is replaced. Encoders
+// added are least preferred when the client accepts multiple media types with the same quality.
+// IMPORTANT: This is not concurrent safe
+func RegisterEncoder(mediaType string, enc Encoder) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for idx := range encoders {
+		if encoders[idx].mediaType == mediaType {
+			encoders[idx].encoder = enc
+			return
+		}
+	}
+	encoders = append(encoders, registeredEncoder{mediaType: mediaType, encoder: enc})
+}
+
+// acceptRange is a single media range of the Accept request header
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses the Accept header value & returns all the media ranges
+func parseAccept(accept string) []acceptRange {
+	parts := strings.Split(accept, ",")
+	ranges := make([]acceptRange, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+		mtype := strings.ToLower(strings.TrimSpace(params[0]))
+		if mtype == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "q" {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+			if err != nil || v < 0 || v > 1 {
+				v = 0
+			}
+			q = v
+		}
+
+		ranges = append(ranges, acceptRange{mediaType: mtype, quality: q})
+	}
+	return ranges
+}
+
+// quality returns the quality of the media type, based on the most specific matching media range
+func quality(mediaType string, ranges []acceptRange) float64 {
+	specificity := -1
+	q := 0.0
+	for _, ar := range ranges {
+		s := -1
+		switch {
+		case ar.mediaType == mediaType:
+			s = 2
+		case ar.mediaType == "*/*":
+			s = 0
+		case strings.HasSuffix(ar.mediaType, "/*") &&
+			strings.HasPrefix(mediaType, strings.TrimSuffix(ar.mediaType, "*")):
+			s = 1
+		}
+
+		if s > specificity {
+			specificity = s
+			q = ar.quality
+		}
+	}
+	return q
+}
+
+// negotiate returns the encoder best matching the Accept header value. It returns nil
+// if none of the encoders are acceptable
+func negotiate(accept string) Encoder {
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0].encoder
+	}
+
+	ranges := parseAccept(accept)
+	var (
+		best        Encoder
+		bestQuality float64
+	)
+	// encoders are iterated in the order of preference, so the first encoder among
+	// the ones with the highest quality is chosen
+	for _, re := range encoders {
+		q := quality(re.mediaType, ranges)
+		if q > bestQuality {
+			best = re.encoder
+			bestQuality = q
+		}
+	}
+
+	return best
+}
+
+// Respond responds with data encoded in the format which best matches the Accept header of
+// the request. JSON, XML, CSV and plain text are supported by default, and more can be added
+// using RegisterEncoder. If none of the formats are acceptable, it responds with status 406.
+// JSON responses are wrapped the same as SendResponse & SendError (if rCode is 400 or more).
+// XML responses are wrapped in `<response><data>...</data><status>200</status></response>`, with
+// `errors` instead of `data` if rCode is 400 or more
+func Respond(w http.ResponseWriter, r *http.Request, data interface{}, rCode int) {
+	w.Header().Add(HeaderVary, HeaderAccept)
+
+	enc := negotiate(r.Header.Get(HeaderAccept))
+	if enc == nil {
+		R406(w, fmt.Sprintf("none of the media types in '%s' are supported", r.Header.Get(HeaderAccept)))
+		return
+	}
+
+	if _, isJSON := enc.(jsonEncoder); isJSON {
+		// the JSON encoder responds directly, refer jsonEncoder.Encode
+		_ = enc.Encode(w, data, rCode)
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err := enc.Encode(buf, data, rCode)
+	if err != nil {
+		R500(w, ErrInternalServer)
+		RequestLogger(r).Error(err)
+		return
+	}
+
+	w = crwAsserter(w, rCode)
+	w.Header().Set(HeaderContentType, enc.ContentType())
+	w.WriteHeader(rCode)
+	_, _ = w.Write(buf.Bytes())
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return JSONContentType
+}
+
+// Encode responds using SendResponse, or SendError if rCode is 400 or more, so that the envelopes &
+// error format configured on the router are respected. Hence w should be the http.ResponseWriter
+func (jsonEncoder) Encode(w io.Writer, data interface{}, rCode int) error {
+	rw, ok := w.(http.ResponseWriter)
+	if !ok {
+		return ErrJSONEncoderWriter
+	}
+
+	if rCode >= http.StatusBadRequest {
+		SendError(rw, data, rCode)
+	} else {
+		SendResponse(rw, data, rCode)
+	}
+	return nil
+}
+
+// xmlOutput is the XML equivalent of dOutput & errOutput
+type xmlOutput struct {
+	XMLName xml.Name    `xml:"response"`
+	Data    interface{} `xml:"data,omitempty"`
+	Errors  interface{} `xml:"errors,omitempty"`
+	Status  int         `xml:"status"`
+}
+
+type xmlEncoder struct{}
+
+func (xmlEncoder) ContentType() string {
+	return XMLContentType
+}
+
+func (xmlEncoder) Encode(w io.Writer, data interface{}, rCode int) error {
+	out := xmlOutput{Status: rCode}
+	if rCode >= http.StatusBadRequest {
+		out.Errors = data
+	} else {
+		out.Data = data
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(out)
+}
+
+type textEncoder struct{}
+
+func (textEncoder) ContentType() string {
+	return TextContentType
+}
+
+func (textEncoder) Encode(w io.Writer, data interface{}, rCode int) error {
+	_, err := fmt.Fprint(w, data)
+	return err
+}
+
+// csvEncoder encodes a struct or a slice of structs as CSV. The header row is the list of
+// field names, or the name provided in the `csv` struct tag. Any other value is encoded as
+// a single column, with the column name "data" or "errors" based on the status code
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return CSVContentType
+}
+
+func (csvEncoder) Encode(w io.Writer, data interface{}, rCode int) error {
+	cw := csv.NewWriter(w)
+	rv := reflect.Indirect(reflect.ValueOf(data))
+
+	var rtype reflect.Type
+	rows := []reflect.Value{rv}
+	switch rv.Kind() {
+	case reflect.Struct:
+		rtype = rv.Type()
+	case reflect.Slice, reflect.Array:
+		rtype = rv.Type().Elem()
+		if rtype.Kind() == reflect.Ptr {
+			rtype = rtype.Elem()
+		}
+		if rtype.Kind() != reflect.Struct {
+			return ErrUnsupportedCSV
+		}
+		rows = make([]reflect.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, reflect.Indirect(rv.Index(i)))
+		}
+	default:
+		column := "data"
+		if rCode >= http.StatusBadRequest {
+			column = "errors"
+		}
+		_ = cw.Write([]string{column})
+		if rv.IsValid() {
+			_ = cw.Write([]string{fmt.Sprint(rv.Interface())})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	header := make([]string, 0, rtype.NumField())
+	fields := make([]int, 0, rtype.NumField())
+	for i := 0; i < rtype.NumField(); i++ {
+		field := rtype.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("csv"); ok {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+	_ = cw.Write(header)
+
+	record := make([]string, len(fields))
+	for _, row := range rows {
+		if !row.IsValid() {
+			// nil pointers in the slice are skipped
+			continue
+		}
+		for idx, fieldIdx := range fields {
+			record[idx] = fmt.Sprint(row.Field(fieldIdx).Interface())
+		}
+		_ = cw.Write(record)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
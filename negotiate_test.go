@@ -0,0 +1,148 @@
+package webgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type negotiateItem struct {
+	ID     int    `json:"id" xml:"id" csv:"id"`
+	Name   string `json:"name" xml:"name" csv:"name"`
+	Secret string `json:"-" xml:"-" csv:"-"`
+}
+
+type yamlEncoder struct{}
+
+func (yamlEncoder) ContentType() string {
+	return "application/yaml"
+}
+
+func (yamlEncoder) Encode(w io.Writer, data interface{}, rCode int) error {
+	_, err := io.WriteString(w, "status: 200\n")
+	return err
+}
+
+func TestRespond(t *testing.T) {
+	RegisterEncoder("application/yaml", yamlEncoder{})
+
+	items := []negotiateItem{{ID: 1, Name: "a", Secret: "s"}, {ID: 2, Name: "b"}}
+	tests := []struct {
+		name       string
+		accept     string
+		wantStatus int
+		wantCType  string
+		wantBody   string
+	}{
+		{
+			name:       "no accept header",
+			wantStatus: http.StatusOK,
+			wantCType:  JSONContentType,
+			wantBody:   `{"data":[{"id":1,"name":"a"},{"id":2,"name":"b"}],"status":200}` + "\n",
+		},
+		{
+			name:       "csv",
+			accept:     "text/csv",
+			wantStatus: http.StatusOK,
+			wantCType:  CSVContentType,
+			wantBody:   "id,name\n1,a\n2,b\n",
+		},
+		{
+			name:       "quality",
+			accept:     "application/json;q=0.5, application/xml;q=0.9, */*;q=0.1",
+			wantStatus: http.StatusOK,
+			wantCType:  XMLContentType,
+		},
+		{
+			name:       "wildcard subtype",
+			accept:     "text/*",
+			wantStatus: http.StatusOK,
+			wantCType:  XMLContentType,
+		},
+		{
+			name:       "excluded with q=0",
+			accept:     "application/json;q=0, */*",
+			wantStatus: http.StatusOK,
+			wantCType:  XMLContentType,
+		},
+		{
+			name:       "registered encoder",
+			accept:     "application/yaml",
+			wantStatus: http.StatusOK,
+			wantCType:  "application/yaml",
+			wantBody:   "status: 200\n",
+		},
+		{
+			name:       "not acceptable",
+			accept:     "image/png",
+			wantStatus: http.StatusNotAcceptable,
+			wantCType:  JSONContentType,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set(HeaderAccept, tt.accept)
+		}
+		Respond(w, r, items, http.StatusOK)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+		}
+		if ctype := w.Header().Get(HeaderContentType); ctype != tt.wantCType {
+			t.Errorf("%s: expected content type '%s', got '%s'", tt.name, tt.wantCType, ctype)
+		}
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Errorf("%s: expected body '%s', got '%s'", tt.name, tt.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestRespondXML(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderAccept, "application/xml")
+	Respond(w, r, "not found", http.StatusNotFound)
+
+	out := struct {
+		XMLName xml.Name `xml:"response"`
+		Errors  string   `xml:"errors"`
+		Status  int      `xml:"status"`
+	}{}
+	err := xml.Unmarshal(w.Body.Bytes(), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Errors != "not found" || out.Status != http.StatusNotFound {
+		t.Errorf("unexpected response '%s'", w.Body.String())
+	}
+
+	// maps are not supported by encoding/xml
+	w = httptest.NewRecorder()
+	Respond(w, r, map[string]string{"a": "b"}, http.StatusOK)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	resp := struct{ Errors string }{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if !strings.Contains(resp.Errors, ErrInternalServer) {
+		t.Errorf("unexpected response '%s'", w.Body.String())
+	}
+}
+
+func TestJSONEncoderWriter(t *testing.T) {
+	t.Parallel()
+	err := jsonEncoder{}.Encode(bytes.NewBuffer(nil), "hello", http.StatusOK)
+	if !errors.Is(err, ErrJSONEncoderWriter) {
+		t.Errorf("expected error '%v', got '%v'", ErrJSONEncoderWriter, err)
+	}
+}
@@ -0,0 +1,336 @@
+package webgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HeaderLink is the response header with the links to related resources, RFC 8288
+	HeaderLink = "Link"
+
+	defaultPerPage = 20
+	defaultMaxPage = 100
+)
+
+var (
+	// ErrInvalidCursor is returned when the cursor of a request is malformed, or is not signed
+	// with the secret of the PaginationConfig
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCursorSecret is returned when signing or verifying a cursor without a secret
+	ErrCursorSecret = errors.New("secret is required for cursors")
+)
+
+// PaginationConfig has the configurations for parsing the pagination parameters of a request
+type PaginationConfig struct {
+	// PageParam & PerPageParam are the query parameters for offset pagination, default
+	// is "page" & "per_page"
+	PageParam    string
+	PerPageParam string
+	// CursorParam & LimitParam are the query parameters for cursor pagination, default
+	// is "cursor" & "limit"
+	CursorParam string
+	LimitParam  string
+	// DefaultPerPage is the number of items per page if not provided in the request, default is 20
+	DefaultPerPage int
+	// MaxPerPage is the maximum number of items per page, larger values are reduced to
+	// MaxPerPage. Default is 100
+	MaxPerPage int
+	// Secret is used to sign the cursors, so that clients cannot tamper with them.
+	// It is required for cursor pagination
+	Secret []byte
+}
+
+func (pc *PaginationConfig) withDefaults() PaginationConfig {
+	cfg := PaginationConfig{}
+	if pc != nil {
+		cfg = *pc
+	}
+	if cfg.PageParam == "" {
+		cfg.PageParam = "page"
+	}
+	if cfg.PerPageParam == "" {
+		cfg.PerPageParam = "per_page"
+	}
+	if cfg.CursorParam == "" {
+		cfg.CursorParam = "cursor"
+	}
+	if cfg.LimitParam == "" {
+		cfg.LimitParam = "limit"
+	}
+	if cfg.DefaultPerPage <= 0 {
+		cfg.DefaultPerPage = defaultPerPage
+	}
+	if cfg.MaxPerPage <= 0 {
+		cfg.MaxPerPage = defaultMaxPage
+	}
+	if cfg.DefaultPerPage > cfg.MaxPerPage {
+		cfg.DefaultPerPage = cfg.MaxPerPage
+	}
+	return cfg
+}
+
+// Pagination has the details of a page of a list response. It is created by ParsePage (offset
+// pagination) or ParseCursor (cursor pagination), and is responded using Paginate
+type Pagination struct {
+	// Page is the current page number, starting from 1
+	Page int `json:"page,omitempty"`
+	// PerPage is the number of items per page, for offset pagination
+	PerPage int `json:"per_page,omitempty"`
+	// Total is the total number of items, for offset pagination. It is required for the
+	// `next` & `last` links
+	Total int `json:"total,omitempty"`
+	// TotalPages is computed from Total & PerPage by Paginate
+	TotalPages int `json:"total_pages,omitempty"`
+
+	// Cursor is the verified value of the cursor of the request, empty for the first page
+	Cursor string `json:"-"`
+	// Limit is the number of items per page, for cursor pagination
+	Limit int `json:"limit,omitempty"`
+	// NextCursor & PrevCursor are the signed cursors of the next & previous pages, refer SetNext
+	// & SetPrev
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+
+	cfg PaginationConfig
+}
+
+// Offset returns the number of items to be skipped for the current page, for offset pagination
+func (p *Pagination) Offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.PerPage
+}
+
+// SetNext sets the cursor of the next page, value is signed before sending to the client
+func (p *Pagination) SetNext(value string) error {
+	cursor, err := SignCursor(p.cfg.Secret, value)
+	if err != nil {
+		return err
+	}
+	p.NextCursor = cursor
+	return nil
+}
+
+// SetPrev sets the cursor of the previous page, value is signed before sending to the client
+func (p *Pagination) SetPrev(value string) error {
+	cursor, err := SignCursor(p.cfg.Secret, value)
+	if err != nil {
+		return err
+	}
+	p.PrevCursor = cursor
+	return nil
+}
+
+func paginationError(param string, err error) error {
+	return &HTTPError{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_query",
+		Message: fmt.Sprintf("invalid value for query parameter '%s'", param),
+		Cause:   err,
+	}
+}
+
+// positiveQueryInt returns the query parameter as a positive integer, or def if it's not available
+func positiveQueryInt(query url.Values, param string, def int) (int, error) {
+	value := query.Get(param)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, paginationError(param, err)
+	}
+	if n < 1 {
+		return 0, paginationError(param, errors.New("should be greater than 0"))
+	}
+	return n, nil
+}
+
+// ParsePage parses the page number & number of items per page from the query parameters of the
+// request, for offset pagination. An invalid value returns an HTTPError with status 400
+func ParsePage(r *http.Request, cfg *PaginationConfig) (*Pagination, error) {
+	p := &Pagination{cfg: cfg.withDefaults()}
+	query := r.URL.Query()
+
+	var err error
+	p.Page, err = positiveQueryInt(query, p.cfg.PageParam, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	p.PerPage, err = positiveQueryInt(query, p.cfg.PerPageParam, p.cfg.DefaultPerPage)
+	if err != nil {
+		return nil, err
+	}
+	if p.PerPage > p.cfg.MaxPerPage {
+		p.PerPage = p.cfg.MaxPerPage
+	}
+
+	return p, nil
+}
+
+// ParseCursor parses & verifies the cursor, and the number of items per page from the query
+// parameters of the request, for cursor pagination. An invalid value returns an HTTPError with
+// status 400
+func ParseCursor(r *http.Request, cfg *PaginationConfig) (*Pagination, error) {
+	p := &Pagination{cfg: cfg.withDefaults()}
+	if len(p.cfg.Secret) == 0 {
+		return nil, ErrCursorSecret
+	}
+	query := r.URL.Query()
+
+	var err error
+	p.Limit, err = positiveQueryInt(query, p.cfg.LimitParam, p.cfg.DefaultPerPage)
+	if err != nil {
+		return nil, err
+	}
+	if p.Limit > p.cfg.MaxPerPage {
+		p.Limit = p.cfg.MaxPerPage
+	}
+
+	if cursor := query.Get(p.cfg.CursorParam); cursor != "" {
+		p.Cursor, err = VerifyCursor(p.cfg.Secret, cursor)
+		if err != nil {
+			return nil, paginationError(p.cfg.CursorParam, err)
+		}
+	}
+
+	return p, nil
+}
+
+func cursorSignature(secret []byte, value string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// SignCursor returns an opaque cursor with the value, signed using HMAC-SHA256
+func SignCursor(secret []byte, value string) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrCursorSecret
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(value)) + "." + enc.EncodeToString(cursorSignature(secret, value)), nil
+}
+
+// VerifyCursor verifies the signature of the cursor and returns its value
+func VerifyCursor(secret []byte, cursor string) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrCursorSecret
+	}
+
+	enc := base64.RawURLEncoding
+	encValue, encSign, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return "", ErrInvalidCursor
+	}
+	value, err := enc.DecodeString(encValue)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	sign, err := enc.DecodeString(encSign)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+
+	if !hmac.Equal(sign, cursorSignature(secret, string(value))) {
+		return "", ErrInvalidCursor
+	}
+	return string(value), nil
+}
+
+// pageURL returns the URL of the current route, with the query parameters of the request updated
+// with params. An empty value removes the query parameter
+func pageURL(r *http.Request, params map[string]string) string {
+	path := r.URL.EscapedPath()
+	if cp := webgoContext(r); cp != nil && cp.Route != nil {
+		if routePath, err := cp.Route.URL(cp.URIParams); err == nil {
+			path = routePath
+		}
+	}
+
+	query := r.URL.Query()
+	for key, value := range params {
+		if value == "" {
+			query.Del(key)
+			continue
+		}
+		query.Set(key, value)
+	}
+
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// links returns the RFC 8288 Link header value with the first, prev, next & last pages
+func (p *Pagination) links(r *http.Request) string {
+	links := make([]string, 0, 4)
+	add := func(rel string, params map[string]string) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, pageURL(r, params), rel))
+	}
+
+	if p.Limit > 0 {
+		limit := strconv.Itoa(p.Limit)
+		add("first", map[string]string{p.cfg.CursorParam: "", p.cfg.LimitParam: limit})
+		if p.PrevCursor != "" {
+			add("prev", map[string]string{p.cfg.CursorParam: p.PrevCursor, p.cfg.LimitParam: limit})
+		}
+		if p.NextCursor != "" {
+			add("next", map[string]string{p.cfg.CursorParam: p.NextCursor, p.cfg.LimitParam: limit})
+		}
+		return strings.Join(links, ", ")
+	}
+
+	perPage := strconv.Itoa(p.PerPage)
+	page := func(n int) map[string]string {
+		return map[string]string{p.cfg.PageParam: strconv.Itoa(n), p.cfg.PerPageParam: perPage}
+	}
+
+	add("first", page(1))
+	if p.Page > 1 {
+		add("prev", page(p.Page-1))
+	}
+	if p.Page < p.TotalPages {
+		add("next", page(p.Page+1))
+	}
+	if p.TotalPages > 0 {
+		add("last", page(p.TotalPages))
+	}
+	return strings.Join(links, ", ")
+}
+
+// Paginate sets the RFC 8288 Link header (first, prev, next & last) of the response, built from
+// the current route & query parameters. The pagination is also added to the response as the
+// `pagination` key of the default DataEnvelope, custom envelopes can use GetPagination
+func Paginate(w http.ResponseWriter, r *http.Request, p *Pagination) {
+	if p.PerPage > 0 && p.Total > 0 {
+		p.TotalPages = (p.Total + p.PerPage - 1) / p.PerPage
+	}
+
+	w.Header().Set(HeaderLink, p.links(r))
+	if cp := webgoContext(r); cp != nil {
+		cp.pagination = p
+	}
+}
+
+// GetPagination returns the pagination set using Paginate, for the request
+func GetPagination(r *http.Request) *Pagination {
+	cp := webgoContext(r)
+	if cp == nil {
+		return nil
+	}
+	return cp.pagination
+}
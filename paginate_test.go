@@ -0,0 +1,156 @@
+package webgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	t.Parallel()
+	cfg := &PaginationConfig{MaxPerPage: 50}
+
+	tests := []struct {
+		name        string
+		url         string
+		wantPage    int
+		wantPerPage int
+		wantErr     bool
+	}{
+		{name: "defaults", url: "/users", wantPage: 1, wantPerPage: 20},
+		{name: "provided", url: "/users?page=3&per_page=10", wantPage: 3, wantPerPage: 10},
+		{name: "max per page", url: "/users?per_page=1000", wantPage: 1, wantPerPage: 50},
+		{name: "zero page", url: "/users?page=0", wantErr: true},
+		{name: "invalid per page", url: "/users?per_page=abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePage(httptest.NewRequest(http.MethodGet, tt.url, nil), cfg)
+			if tt.wantErr {
+				var herr *HTTPError
+				if !errors.As(err, &herr) || herr.Status != http.StatusBadRequest {
+					t.Fatalf("expected a 400 HTTPError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Page != tt.wantPage || p.PerPage != tt.wantPerPage {
+				t.Errorf("expected page %d/%d, got %d/%d", tt.wantPage, tt.wantPerPage, p.Page, p.PerPage)
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+	secret := []byte("secret")
+	cursor, err := SignCursor(secret, "user-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := VerifyCursor(secret, cursor)
+	if err != nil || value != "user-42" {
+		t.Fatalf("expected 'user-42', got '%s', %v", value, err)
+	}
+
+	_, err = VerifyCursor([]byte("other"), cursor)
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for a different secret, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users?cursor="+cursor+"&limit=5", nil)
+	p, err := ParseCursor(req, &PaginationConfig{Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Cursor != "user-42" || p.Limit != 5 {
+		t.Errorf("expected cursor 'user-42' & limit 5, got '%s' & %d", p.Cursor, p.Limit)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users?cursor=tampered."+strings.Split(cursor, ".")[1], nil)
+	_, err = ParseCursor(req, &PaginationConfig{Secret: secret})
+	if err == nil {
+		t.Error("expected error for a tampered cursor")
+	}
+
+	_, err = ParseCursor(req, nil)
+	if !errors.Is(err, ErrCursorSecret) {
+		t.Errorf("expected ErrCursorSecret, got %v", err)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+	secret := []byte("secret")
+	router := NewRouter(&Config{},
+		&Route{
+			Name:    "posts",
+			Method:  http.MethodGet,
+			Pattern: "/users/:userID/posts",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					p, err := ParsePage(r, nil)
+					if err != nil {
+						R400(w, err.Error())
+						return
+					}
+					p.Total = 45
+					Paginate(w, r, p)
+					R200(w, []string{"a", "b"})
+				},
+			},
+		},
+		&Route{
+			Name:    "comments",
+			Method:  http.MethodGet,
+			Pattern: "/comments",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					p, err := ParseCursor(r, &PaginationConfig{Secret: secret})
+					if err != nil {
+						R400(w, err.Error())
+						return
+					}
+					_ = p.SetNext("c-10")
+					Paginate(w, r, p)
+					R200(w, []string{"a", "b"})
+				},
+			},
+		},
+	)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1/posts?page=2&sort=asc", nil))
+	wantLink := `</users/1/posts?page=1&per_page=20&sort=asc>; rel="first", ` +
+		`</users/1/posts?page=1&per_page=20&sort=asc>; rel="prev", ` +
+		`</users/1/posts?page=3&per_page=20&sort=asc>; rel="next", ` +
+		`</users/1/posts?page=3&per_page=20&sort=asc>; rel="last"`
+	if got := w.Header().Get(HeaderLink); got != wantLink {
+		t.Errorf("expected Link '%s', got '%s'", wantLink, got)
+	}
+
+	body := struct {
+		Pagination Pagination `json:"pagination"`
+	}{}
+	err := json.NewDecoder(w.Body).Decode(&body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.Pagination.Page != 2 || body.Pagination.TotalPages != 3 || body.Pagination.Total != 45 {
+		t.Errorf("unexpected pagination in response, %+v", body.Pagination)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/comments?limit=10", nil))
+	next, _ := SignCursor(secret, "c-10")
+	wantLink = `</comments?limit=10>; rel="first", </comments?cursor=` + next + `&limit=10>; rel="next"`
+	if got := w.Header().Get(HeaderLink); got != wantLink {
+		t.Errorf("expected Link '%s', got '%s'", wantLink, got)
+	}
+}
@@ -0,0 +1,112 @@
+package webgo
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// HeaderIfMatch is the request header with the entity tags, one of which should match the
+	// current entity tag of the resource for the request to be processed
+	HeaderIfMatch = "If-Match"
+	// HeaderIfUnmodifiedSince is the request header with the time after which the resource should
+	// not have been modified, for the request to be processed
+	HeaderIfUnmodifiedSince = "If-Unmodified-Since"
+)
+
+var (
+	// ErrPreconditionFailed is returned when the preconditions of the request do not match the
+	// current state of the resource
+	ErrPreconditionFailed = NewHTTPError(http.StatusPreconditionFailed, "precondition_failed", "")
+	// ErrPreconditionRequired is returned when the route requires preconditions, but the request
+	// has none of them
+	ErrPreconditionRequired = NewHTTPError(http.StatusPreconditionRequired, "precondition_required", "")
+)
+
+// PreconditionPolicy defines how the preconditions of the requests to a route are evaluated
+type PreconditionPolicy int
+
+const (
+	// PreconditionOptional evaluates the preconditions only if the request has them
+	PreconditionOptional PreconditionPolicy = iota
+	// PreconditionRequired responds with 428 Precondition Required to unsafe requests (e.g. PUT,
+	// PATCH, DELETE), which have neither If-Match nor If-Unmodified-Since
+	PreconditionRequired
+)
+
+// ValidatorFunc returns the current entity tag (quoted, e.g. `"v1"`) and/or the last modified time
+// of the resource requested. Either of them can be empty, if not available
+type ValidatorFunc func(r *http.Request) (etag string, modtime time.Time, err error)
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// EvaluatePreconditions evaluates If-Match, If-Unmodified-Since & If-None-Match of the request
+// against the current entity tag (quoted, e.g. `"v1"`) and the last modified time of the resource,
+// as per RFC 9110, 13.2.2. It returns ErrPreconditionFailed if the preconditions do not match, and
+// ErrPreconditionRequired if the route's policy is PreconditionRequired and there are none.
+// An empty etag or a zero modtime means the validator is not available
+func EvaluatePreconditions(r *http.Request, etag string, modtime time.Time) error {
+	if im := r.Header.Get(HeaderIfMatch); im != "" {
+		if !etagMatch(im, etag, false) {
+			return ErrPreconditionFailed
+		}
+	} else if ius := r.Header.Get(HeaderIfUnmodifiedSince); ius != "" {
+		t, err := http.ParseTime(ius)
+		if err == nil && !modtime.IsZero() && modtime.Truncate(time.Second).After(t) {
+			return ErrPreconditionFailed
+		}
+	} else if !safeMethod(r.Method) {
+		if cp := webgoContext(r); cp != nil && cp.Route != nil && cp.Route.Preconditions == PreconditionRequired {
+			return ErrPreconditionRequired
+		}
+	}
+
+	// If-None-Match of GET & HEAD requests are evaluated by NotModified
+	if inm := r.Header.Get(HeaderIfNoneMatch); inm != "" && !safeMethod(r.Method) {
+		if etagMatch(inm, etag, true) {
+			return ErrPreconditionFailed
+		}
+	}
+
+	return nil
+}
+
+// CheckPreconditions evaluates the preconditions of the request, refer EvaluatePreconditions. If
+// they fail, the error is responded using the router's ErrorHandler and it returns false. e.g.
+//
+//	if !webgo.CheckPreconditions(w, r, `"`+user.Version+`"`, user.UpdatedAt) {
+//		return
+//	}
+func CheckPreconditions(w http.ResponseWriter, r *http.Request, etag string, modtime time.Time) bool {
+	err := EvaluatePreconditions(r, etag, modtime)
+	if err != nil {
+		HandleError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// Preconditions returns a middleware, which evaluates the preconditions of unsafe requests (e.g.
+// PUT, PATCH, DELETE) against the current validators of the resource returned by current. The
+// request is passed on to the handlers only if the preconditions match
+func Preconditions(current ValidatorFunc) Middleware {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if safeMethod(r.Method) {
+			next(w, r)
+			return
+		}
+
+		etag, modtime, err := current(r)
+		if err != nil {
+			HandleError(w, r, err)
+			return
+		}
+
+		if !CheckPreconditions(w, r, etag, modtime) {
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,77 @@
+package webgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePreconditions(t *testing.T) {
+	t.Parallel()
+	modtime := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	var current ValidatorFunc = func(r *http.Request) (string, time.Time, error) {
+		return `"v2"`, modtime, nil
+	}
+	updated := func(w http.ResponseWriter, r *http.Request) {
+		SendResponse(w, "updated", http.StatusOK)
+	}
+	router := NewRouter(&Config{},
+		&Route{
+			Name:          "required",
+			Method:        http.MethodPut,
+			Pattern:       "/required",
+			Preconditions: PreconditionRequired,
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					if !CheckPreconditions(w, r, `"v2"`, modtime) {
+						return
+					}
+					updated(w, r)
+				},
+			},
+		},
+		&Route{
+			Name:    "optional",
+			Method:  http.MethodPut,
+			Pattern: "/optional",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					Preconditions(current)(w, r, updated)
+				},
+			},
+		},
+	)
+
+	tests := []struct {
+		name       string
+		url        string
+		header     map[string]string
+		wantStatus int
+	}{
+		{name: "required, missing", url: "/required", wantStatus: http.StatusPreconditionRequired},
+		{name: "required, match", url: "/required", header: map[string]string{HeaderIfMatch: `"v1", "v2"`}, wantStatus: http.StatusOK},
+		{name: "required, no match", url: "/required", header: map[string]string{HeaderIfMatch: `"v1"`}, wantStatus: http.StatusPreconditionFailed},
+		{name: "required, weak", url: "/required", header: map[string]string{HeaderIfMatch: `W/"v2"`}, wantStatus: http.StatusPreconditionFailed},
+		{name: "required, wildcard", url: "/required", header: map[string]string{HeaderIfMatch: "*"}, wantStatus: http.StatusOK},
+		{name: "optional, missing", url: "/optional", wantStatus: http.StatusOK},
+		{name: "optional, unmodified", url: "/optional", header: map[string]string{HeaderIfUnmodifiedSince: modtime.Format(http.TimeFormat)}, wantStatus: http.StatusOK},
+		{name: "optional, modified", url: "/optional", header: map[string]string{HeaderIfUnmodifiedSince: modtime.Add(-time.Hour).Format(http.TimeFormat)}, wantStatus: http.StatusPreconditionFailed},
+		{name: "optional, if-none-match", url: "/optional", header: map[string]string{HeaderIfNoneMatch: "*"}, wantStatus: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, tt.url, nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status '%d', got '%d', body: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package webgo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	// ProblemJSONContentType is the MIME type of RFC 9457 problem details
+	ProblemJSONContentType = "application/problem+json"
+
+	// problemTypeDefault is the problem type when there is no additional semantics
+	problemTypeDefault = "about:blank"
+)
+
+// ProblemDetailsConfig enables RFC 9457 (https://www.rfc-editor.org/rfc/rfc9457) problem details
+// for all error responses of a router. i.e. SendError and all the Rxxx helpers which use it
+type ProblemDetailsConfig struct {
+	// TypeBaseURI is prefixed to the Code of an HTTPError to construct the problem type. e.g.
+	// "https://example.com/problems/". If empty, the type is "about:blank"
+	TypeBaseURI string
+	// Extensions are added to every problem responded. e.g. a link to the API documentation
+	Extensions map[string]interface{}
+}
+
+// Problem is the RFC 9457 problem details object
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions are additional members of the problem, which are serialized as top level members
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON serializes the extension members alongside the standard members. Extensions cannot
+// overwrite the standard members
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	standard := map[string]string{
+		"type":     p.Type,
+		"title":    p.Title,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	}
+	for key, value := range standard {
+		delete(members, key)
+		if value != "" {
+			members[key] = value
+		}
+	}
+
+	delete(members, "status")
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+
+	return json.Marshal(members)
+}
+
+// newProblem converts the payload of SendError to a Problem
+func newProblem(cfg *ProblemDetailsConfig, r *http.Request, data interface{}, rCode int) Problem {
+	p := Problem{}
+	extensions := make(map[string]interface{}, len(cfg.Extensions))
+	for key, value := range cfg.Extensions {
+		extensions[key] = value
+	}
+
+	var herr *HTTPError
+	switch d := data.(type) {
+	case Problem:
+		p = d
+	case *Problem:
+		p = *d
+	case string:
+		p.Detail = d
+	case error:
+		if errors.As(d, &herr) {
+			p.Detail = herr.Message
+			if herr.Code != "" {
+				extensions["code"] = herr.Code
+				if cfg.TypeBaseURI != "" {
+					p.Type = cfg.TypeBaseURI + herr.Code
+				}
+			}
+			if herr.Details != nil {
+				extensions["details"] = herr.Details
+			}
+		} else {
+			p.Detail = d.Error()
+		}
+	case nil:
+	default:
+		extensions["errors"] = d
+	}
+
+	for key, value := range p.Extensions {
+		extensions[key] = value
+	}
+	if len(extensions) > 0 {
+		p.Extensions = extensions
+	}
+
+	if p.Type == "" {
+		p.Type = problemTypeDefault
+	}
+	if p.Status == 0 {
+		p.Status = rCode
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.RequestURI()
+	}
+
+	return p
+}
+
+// sendProblem responds with the payload of SendError as an RFC 9457 problem
+func sendProblem(crw *customResponseWriter, cfg *ProblemDetailsConfig, data interface{}, rCode int) {
+	crw.Header().Set(HeaderContentType, ProblemJSONContentType)
+	err := codec.Encode(crw, newProblem(cfg, crw.req, data, rCode))
+	if err != nil {
+		/*
+			In case of encoding error, send "internal server error" and
+			log the actual error. If the header is already written, it's a
+			write error & the response cannot be changed anymore
+		*/
+		if !crw.headerWritten {
+			R500(crw, ErrInternalServer)
+		}
+		RequestLogger(crw.req).Error(err)
+	}
+}
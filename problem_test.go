@@ -0,0 +1,113 @@
+package webgo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemDetails(t *testing.T) {
+	t.Parallel()
+	router := NewRouter(&Config{}, &Route{
+		Name:    "problem",
+		Method:  http.MethodGet,
+		Pattern: "/problem/:kind",
+		HandlersE: []HandlerFuncE{
+			func(w http.ResponseWriter, r *http.Request) error {
+				switch Context(r).Params()["kind"] {
+				case "httperror":
+					return &HTTPError{
+						Status:  http.StatusConflict,
+						Code:    "duplicate",
+						Message: "user already exists",
+						Details: []string{"email"},
+					}
+				case "r404":
+					R404(w, "user not found")
+				case "custom":
+					SendError(w, &Problem{Title: "Custom", Extensions: map[string]interface{}{"balance": 30}}, http.StatusForbidden)
+				}
+				return nil
+			},
+		},
+	})
+	router.ProblemDetails = &ProblemDetailsConfig{
+		TypeBaseURI: "https://example.com/problems/",
+		Extensions:  map[string]interface{}{"docs": "https://example.com/docs", "status": "ignored"},
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want map[string]interface{}
+	}{
+		{
+			name: "http error",
+			url:  "/problem/httperror",
+			want: map[string]interface{}{
+				"type":     "https://example.com/problems/duplicate",
+				"title":    "Conflict",
+				"status":   float64(http.StatusConflict),
+				"detail":   "user already exists",
+				"instance": "/problem/httperror",
+				"code":     "duplicate",
+				"details":  []interface{}{"email"},
+				"docs":     "https://example.com/docs",
+			},
+		},
+		{
+			name: "R404",
+			url:  "/problem/r404?id=1",
+			want: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Not Found",
+				"status":   float64(http.StatusNotFound),
+				"detail":   "user not found",
+				"instance": "/problem/r404?id=1",
+				"docs":     "https://example.com/docs",
+			},
+		},
+		{
+			name: "custom problem",
+			url:  "/problem/custom",
+			want: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Custom",
+				"status":   float64(http.StatusForbidden),
+				"instance": "/problem/custom",
+				"balance":  float64(30),
+				"docs":     "https://example.com/docs",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if ctype := w.Header().Get(HeaderContentType); ctype != ProblemJSONContentType {
+			t.Errorf("%s: expected content type '%s', got '%s'", tt.name, ProblemJSONContentType, ctype)
+		}
+
+		got := map[string]interface{}{}
+		err := json.Unmarshal(w.Body.Bytes(), &got)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: expected '%v', got '%v'", tt.name, tt.want, got)
+		}
+		for key, value := range tt.want {
+			gotJSON, _ := json.Marshal(got[key])
+			wantJSON, _ := json.Marshal(value)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("%s: expected '%s' to be '%s', got '%s'", tt.name, key, wantJSON, gotJSON)
+			}
+		}
+		if int(got["status"].(float64)) != w.Code {
+			t.Errorf("%s: expected status %d, got %d", tt.name, int(got["status"].(float64)), w.Code)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package webgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrInvalidRedirectStatus is logged when a redirect is attempted with a non redirect status code
+	ErrInvalidRedirectStatus = errors.New("invalid redirect status code")
+	// ErrRedirectNotAllowed is returned when the redirect target is neither relative, nor
+	// one of the allowed hosts
+	ErrRedirectNotAllowed = errors.New("redirect target is not allowed")
+)
+
+// validRedirect returns true if rCode is one of the redirect status codes supported
+func validRedirect(rCode int) bool {
+	switch rCode {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// redirectAllowed checks if target is safe to redirect to, i.e. it is a relative URL, or the host
+// is the same as the request's or is one of the allowed hosts. An allowed host "*" allows all hosts
+func redirectAllowed(r *http.Request, target string, allowedHosts []string) bool {
+	// browsers treat backslashes as forward slashes, so "/\example.com" is the same as "//example.com"
+	normalized := strings.ReplaceAll(target, `\`, "/")
+	for _, c := range normalized {
+		// control characters are stripped by browsers, and can be used to bypass the checks
+		if c < 0x20 || c == 0x7f {
+			return false
+		}
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme == "" && u.Host == "" && !strings.HasPrefix(normalized, "//") {
+		return true
+	}
+
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+
+	if r != nil && strings.EqualFold(host, stripPort(r.Host)) {
+		return true
+	}
+
+	for _, ah := range allowedHosts {
+		ah = strings.ToLower(strings.TrimSpace(ah))
+		if ah == "*" || ah == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stripPort(hostport string) string {
+	u := url.URL{Host: hostport}
+	return u.Hostname()
+}
+
+// Redirect redirects the client to target with the redirect status code rCode (301, 302, 303, 307
+// or 308). To prevent open redirects, target should be a relative URL, or an absolute URL with the
+// same host as the request or one of the RedirectAllowedHosts of the router. A target which is not
+// allowed is responded with 400, and an invalid status code with 500
+func Redirect(w http.ResponseWriter, r *http.Request, target string, rCode int) {
+	if !validRedirect(rCode) {
+		R500(w, ErrInternalServer)
+		RequestLogger(r).Error(fmt.Sprintf("%s: %d", ErrInvalidRedirectStatus.Error(), rCode))
+		return
+	}
+
+	var allowedHosts []string
+	if cp := webgoContext(r); cp != nil && cp.router != nil {
+		allowedHosts = cp.router.RedirectAllowedHosts
+	}
+
+	if !redirectAllowed(r, target, allowedHosts) {
+		R400(w, ErrRedirectNotAllowed.Error())
+		return
+	}
+
+	http.Redirect(crwAsserter(w, rCode), r, target, rCode)
+}
+
+// RedirectToRoute redirects the client to the route with the given name, refer Route.URL
+func RedirectToRoute(w http.ResponseWriter, r *http.Request, name string, params map[string]string, rCode int) {
+	cp := webgoContext(r)
+	if cp == nil || cp.router == nil {
+		R500(w, ErrInternalServer)
+		RequestLogger(r).Error(fmt.Sprintf("%s: '%s'", ErrRouteNotFound.Error(), name))
+		return
+	}
+
+	target, err := cp.router.URL(name, params)
+	if err != nil {
+		R500(w, ErrInternalServer)
+		RequestLogger(r).Error(err.Error())
+		return
+	}
+
+	Redirect(w, r, target, rCode)
+}
+
+// R301 - Permanent redirect, the client may change the request method to GET
+func R301(w http.ResponseWriter, r *http.Request, target string) {
+	Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// R303 - See other, the client should use GET to request the target
+func R303(w http.ResponseWriter, r *http.Request, target string) {
+	Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// R307 - Temporary redirect, the client should not change the request method
+func R307(w http.ResponseWriter, r *http.Request, target string) {
+	Redirect(w, r, target, http.StatusTemporaryRedirect)
+}
+
+// R308 - Permanent redirect, the client should not change the request method
+func R308(w http.ResponseWriter, r *http.Request, target string) {
+	Redirect(w, r, target, http.StatusPermanentRedirect)
+}
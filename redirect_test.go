@@ -0,0 +1,72 @@
+package webgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirect(t *testing.T) {
+	t.Parallel()
+	router := NewRouter(&Config{},
+		&Route{
+			Name:    "redirect",
+			Method:  http.MethodGet,
+			Pattern: "/redirect",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					status := http.StatusFound
+					if r.URL.Query().Get("status") == "invalid" {
+						status = http.StatusOK
+					}
+					Redirect(w, r, r.URL.Query().Get("next"), status)
+				},
+			},
+		},
+		&Route{
+			Name:    "named",
+			Method:  http.MethodGet,
+			Pattern: "/named",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					RedirectToRoute(w, r, "user", map[string]string{"userID": "10"}, http.StatusSeeOther)
+				},
+			},
+		},
+		&Route{
+			Name:     "user",
+			Method:   http.MethodGet,
+			Pattern:  "/users/:userID",
+			Handlers: []http.HandlerFunc{dummyHandler},
+		},
+	)
+	router.RedirectAllowedHosts = []string{"accounts.example.com"}
+
+	tests := []struct {
+		name         string
+		url          string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "relative", url: "/redirect?next=/home", wantStatus: http.StatusFound, wantLocation: "/home"},
+		{name: "same host", url: "http://example.com/redirect?next=http://example.com/a", wantStatus: http.StatusFound, wantLocation: "http://example.com/a"},
+		{name: "allowed host", url: "/redirect?next=https://accounts.example.com/login", wantStatus: http.StatusFound, wantLocation: "https://accounts.example.com/login"},
+		{name: "other host", url: "/redirect?next=https://evil.com", wantStatus: http.StatusBadRequest},
+		{name: "protocol relative", url: "/redirect?next=//evil.com", wantStatus: http.StatusBadRequest},
+		{name: "backslash", url: "/redirect?next=/%5Cevil.com", wantStatus: http.StatusBadRequest},
+		{name: "javascript", url: "/redirect?next=javascript:alert(1)", wantStatus: http.StatusBadRequest},
+		{name: "invalid status", url: "/redirect?next=/home&status=invalid", wantStatus: http.StatusInternalServerError},
+		{name: "named route", url: "/named", wantStatus: http.StatusSeeOther, wantLocation: "/users/10"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, w.Code)
+		}
+		if location := w.Header().Get("Location"); location != tt.wantLocation {
+			t.Errorf("%s: expected location '%s', got '%s'", tt.name, tt.wantLocation, location)
+		}
+	}
+}
@@ -1,10 +1,10 @@
 package webgo
 
 import (
-	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
+	"sync"
 )
 
 // ErrorData used to render the error page
@@ -15,8 +15,9 @@ type ErrorData struct {
 
 // dOutput is the standard/valid output wrapped in `{data: <payload>, status: <http response status>}`
 type dOutput struct {
-	Data   interface{} `json:"data"`
-	Status int         `json:"status"`
+	Data       interface{} `json:"data"`
+	Status     int         `json:"status"`
+	Pagination *Pagination `json:"pagination,omitempty"`
 }
 
 // errOutput is the error output wrapped in `{errors:<errors>, status: <http response status>}`
@@ -42,70 +43,150 @@ func SendHeader(w http.ResponseWriter, rCode int) {
 	w.WriteHeader(rCode)
 }
 
-func crwAsserter(w http.ResponseWriter, rCode int) http.ResponseWriter {
+func crwAsserter(w http.ResponseWriter, rCode int) *customResponseWriter {
 	if crw, ok := w.(*customResponseWriter); ok {
-		crw.statusCode = rCode
+		// the status code is retained once the header is written, it's the status code responded
+		if !crw.headerWritten {
+			crw.statusCode = rCode
+		}
 		return crw
 	}
 
-	return newCRW(w, rCode)
+	crw := newCRW(w, rCode)
+	// if w wraps webgo's response writer, the router & request are retained so that
+	// the router's configurations are respected
+	if inner := findCRW(w); inner != nil {
+		crw.router = inner.router
+		crw.req = inner.req
+	}
+	return crw
 }
 
 // Send sends a completely custom response without wrapping in the
 // `{data: <data>, status: <int>` struct
 func Send(w http.ResponseWriter, contentType string, data interface{}, rCode int) {
-	w = crwAsserter(w, rCode)
+	crw := crwAsserter(w, rCode)
+	w = crw
 	w.Header().Set(HeaderContentType, contentType)
 	_, err := fmt.Fprint(w, data)
 	if err != nil {
+		// a buffered response can be replaced cleanly, otherwise the error is appended
+		crw.discardBuffer()
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = w.Write([]byte(ErrInternalServer))
-		LOGHANDLER.Error(err)
+		RequestLogger(crw.req).Error(err)
 	}
 }
 
 // SendResponse is used to respond to any request (JSON response) based on the code, data etc.
+// The data is wrapped using the DataEnvelope of the router serving the request
 func SendResponse(w http.ResponseWriter, data interface{}, rCode int) {
-	w = crwAsserter(w, rCode)
+	crw := crwAsserter(w, rCode)
+	w = crw
 	w.Header().Add(HeaderContentType, JSONContentType)
-	err := json.NewEncoder(w).Encode(dOutput{Data: data, Status: rCode})
+	err := codec.Encode(w, dataEnvelope(crw, data, rCode))
 	if err != nil {
 		/*
 			In case of encoding error, send "internal server error" and
-			log the actual error.
+			log the actual error. If the header is already written, it's a
+			write error & the response cannot be changed anymore
 		*/
-		R500(w, ErrInternalServer)
-		LOGHANDLER.Error(err)
+		if !crw.headerWritten {
+			R500(w, ErrInternalServer)
+		}
+		RequestLogger(crw.req).Error(err)
 	}
 }
 
-// SendError is used to respond to any request with an error
+// SendError is used to respond to any request with an error. The data is wrapped using the
+// ErrorEnvelope of the router serving the request. If ProblemDetails is enabled on the router,
+// the error is responded as an RFC 9457 problem instead
 func SendError(w http.ResponseWriter, data interface{}, rCode int) {
-	w = crwAsserter(w, rCode)
+	crw := crwAsserter(w, rCode)
+	if crw.router != nil && crw.router.ProblemDetails != nil {
+		sendProblem(crw, crw.router.ProblemDetails, data, rCode)
+		return
+	}
+
+	w = crw
 	w.Header().Add(HeaderContentType, JSONContentType)
-	err := json.NewEncoder(w).Encode(errOutput{data, rCode})
+	err := codec.Encode(w, errorEnvelope(crw, data, rCode))
 	if err != nil {
 		/*
 			In case of encoding error, send "internal server error" and
-			log the actual error.
+			log the actual error. If the header is already written, it's a
+			write error & the response cannot be changed anymore
 		*/
-		R500(w, ErrInternalServer)
-		LOGHANDLER.Error(err)
+		if !crw.headerWritten {
+			R500(w, ErrInternalServer)
+		}
+		RequestLogger(crw.req).Error(err)
 	}
 }
 
-// Render is used for rendering templates (HTML)
+// pristineTemplates has a clone of every template rendered using Render, made before the template
+// is executed. Since an html template cannot be cloned once it's executed, these are cloned to add
+// the functions specific to a request
+var pristineTemplates sync.Map
+
+// pristineTemplate returns the clone of tpl which is never executed
+func pristineTemplate(tpl *template.Template) (*template.Template, error) {
+	if pristine, ok := pristineTemplates.Load(tpl); ok {
+		return pristine.(*template.Template), nil
+	}
+
+	clone, err := tpl.Clone()
+	if err != nil {
+		// the template may have been cloned & executed concurrently by Render
+		if pristine, ok := pristineTemplates.Load(tpl); ok {
+			return pristine.(*template.Template), nil
+		}
+		return nil, err
+	}
+	pristine, _ := pristineTemplates.LoadOrStore(tpl, clone)
+	return pristine.(*template.Template), nil
+}
+
+// Render is used for rendering templates (HTML). If the request has its own functions (refer
+// AddTemplateFuncs), they are added to a clone of the template, so the functions should be declared
+// when the template is parsed. An html template cannot be cloned once it's executed, so a clone of
+// every template is retained when it's rendered first, and templates should be parsed only once
+// (e.g. at startup). A template executed elsewhere before it's rendered, cannot be rendered with
+// the functions of a request and is responded with 500
 func Render(w http.ResponseWriter, data interface{}, rCode int, tpl *template.Template) {
-	w = crwAsserter(w, rCode)
+	crw := crwAsserter(w, rCode)
+	w = crw
+
+	var cp *ContextPayload
+	if crw.req != nil {
+		cp = webgoContext(crw.req)
+	}
+
+	pristine, err := pristineTemplate(tpl)
+	if cp != nil && len(cp.templateFuncs) > 0 {
+		var clone *template.Template
+		if err == nil {
+			clone, err = pristine.Clone()
+		}
+		if err != nil {
+			// rendering the template as is would silently ignore the functions of the request
+			Send(w, "text/plain", ErrInternalServer, http.StatusInternalServerError)
+			RequestLogger(crw.req).Error(fmt.Sprintf("render: template '%s' cannot be cloned: %s", tpl.Name(), err.Error()))
+			return
+		}
+		tpl = clone.Funcs(requestFuncs(crw.req))
+	}
 
 	// In case of HTML response, setting appropriate header type for text/HTML response
 	w.Header().Set(HeaderContentType, HTMLContentType)
 
 	// Rendering an HTML template with appropriate data
-	err := tpl.Execute(w, data)
+	err = tpl.Execute(w, data)
 	if err != nil {
+		// a partially rendered template is discarded if the response is buffered
+		crw.discardBuffer()
 		Send(w, "text/plain", ErrInternalServer, http.StatusInternalServerError)
-		LOGHANDLER.Error(err.Error())
+		RequestLogger(crw.req).Error(err.Error())
 	}
 }
 
@@ -125,6 +206,8 @@ func R204(w http.ResponseWriter) {
 }
 
 // R302 - Temporary redirect
+//
+// Deprecated: R302 does not set the Location header, use Redirect with http.StatusFound instead
 func R302(w http.ResponseWriter, data interface{}) {
 	SendResponse(w, data, http.StatusFound)
 }
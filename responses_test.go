@@ -581,3 +581,102 @@ func TestResponsehelpers(t *testing.T) {
 	}
 
 }
+
+func TestRenderRequestFuncs(t *testing.T) {
+	t.Parallel()
+	tpl, err := template.New("page").Funcs(template.FuncMap{
+		"csp_nonce": func() string { return "" },
+	}).Parse(`<script nonce="{{csp_nonce}}"></script>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter(&Config{}, &Route{
+		Name:    "page",
+		Method:  http.MethodGet,
+		Pattern: "/page/:nonce",
+		Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+			nonce := Context(r).Params()["nonce"]
+			AddTemplateFuncs(r, template.FuncMap{
+				"csp_nonce": func() string { return nonce },
+			})
+			Render(w, nil, http.StatusOK, tpl)
+		}},
+	}, &Route{
+		Name:    "plain",
+		Method:  http.MethodGet,
+		Pattern: "/plain",
+		Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+			Render(w, nil, http.StatusOK, tpl)
+		}},
+	})
+
+	// rendering without the functions of the request first, should not prevent adding them later
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/plain", nil))
+	if want := `<script nonce=""></script>`; w.Body.String() != want {
+		t.Errorf("expected '%s', got '%s'", want, w.Body.String())
+	}
+
+	for _, nonce := range []string{"abc", "def"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page/"+nonce, nil))
+		want := `<script nonce="` + nonce + `"></script>`
+		if w.Body.String() != want {
+			t.Errorf("expected '%s', got '%s'", want, w.Body.String())
+		}
+	}
+}
+
+func TestRenderExecutedTemplate(t *testing.T) {
+	t.Parallel()
+	tpl, err := template.New("page").Funcs(template.FuncMap{
+		"csp_nonce": func() string { return "" },
+	}).Parse(`<script nonce="{{csp_nonce}}"></script>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an html template cannot be cloned once it's executed
+	err = tpl.Execute(ioutil.Discard, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter(&Config{}, &Route{
+		Name:    "page",
+		Method:  http.MethodGet,
+		Pattern: "/page",
+		Handlers: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+			AddTemplateFuncs(r, template.FuncMap{
+				"csp_nonce": func() string { return "abc" },
+			})
+			Render(w, nil, http.StatusOK, tpl)
+		}},
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/page", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d: %s", http.StatusInternalServerError, w.Code, w.Body.String())
+	}
+
+	// without the functions of the request, the template is rendered as is
+	w = httptest.NewRecorder()
+	Render(w, nil, http.StatusOK, tpl)
+	if want := `<script nonce=""></script>`; w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("expected '%s', got '%d': %s", want, w.Code, w.Body.String())
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	tpl, err := template.New("page").Parse(`<html><body><h1>{{.Title}}</h1><p>{{.Body}}</p></body></html>`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := map[string]string{"Title": "hello", "Body": "<world>"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Render(httptest.NewRecorder(), data, http.StatusOK, tpl)
+	}
+}
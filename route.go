@@ -2,9 +2,20 @@ package webgo
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+)
+
+var (
+	// ErrMissingURIParam is returned when building the URL of a route, without providing
+	// all the named URI parameters
+	ErrMissingURIParam = errors.New("missing URI parameter")
+	// ErrRouteNotFound is returned when there is no route with the name provided
+	ErrRouteNotFound = errors.New("route not found")
 )
 
 // Route defines a route for each API
@@ -26,6 +37,32 @@ type Route struct {
 	// subsequent writes from the following handlers will be ignored
 	Handlers []http.HandlerFunc
 
+	// HandlersE is a slice of HandlerFuncE, which are executed after Handlers. Any error returned
+	// by them is responded using the router's ErrorHandler
+	HandlersE []HandlerFuncE
+
+	// ETag overrides the ETag configuration of the router for this route, refer ETagConfig
+	ETag *ETagConfig
+
+	// BufferSize if more than 0, the response is held in memory till all the handlers & middleware
+	// are executed. So that middleware can modify the response header after the handlers, and a
+	// partially written response can be replaced with an error (refer DiscardResponse). Responses
+	// larger than BufferSize (in bytes) are streamed to the client
+	BufferSize int64
+
+	// Meta is the metadata of the route, e.g. configurations for middleware like rate limits.
+	// The keys are defined by the respective middleware
+	Meta map[string]interface{}
+
+	// Preconditions is the policy for evaluating the preconditions (If-Match, If-Unmodified-Since)
+	// of the requests to this route, refer EvaluatePreconditions
+	Preconditions PreconditionPolicy
+
+	// Timeout is the maximum duration for the handlers of this route to respond, when the timeout
+	// middleware is used. It overrides the middleware's default timeout. A negative value disables
+	// the timeout, as well as the server's WriteTimeout for this route, e.g. for streams
+	Timeout time.Duration
+
 	hasWildcard bool
 	fragments   []uriFragment
 	paramsCount int
@@ -199,6 +236,31 @@ func (r *Route) matchWithWildcard(requestURI string) (bool, map[string]string) {
 	return true, params
 }
 
+// URL returns the URI path of the route, with the named URI parameters replaced by the values
+// in params. Values are path escaped, and the value of a wildcard parameter can have '/'
+func (r *Route) URL(params map[string]string) (string, error) {
+	fragments := strings.Split(r.Pattern, "/")
+	for idx, fragment := range fragments {
+		if !strings.HasPrefix(fragment, ":") {
+			continue
+		}
+
+		key := strings.TrimSuffix(strings.TrimPrefix(fragment, ":"), "*")
+		value, ok := params[key]
+		if !ok {
+			return "", fmt.Errorf("%w '%s' for route '%s'", ErrMissingURIParam, key, r.Name)
+		}
+
+		parts := strings.Split(value, "/")
+		for i := range parts {
+			parts[i] = url.PathEscape(parts[i])
+		}
+		fragments[idx] = strings.Join(parts, "/")
+	}
+
+	return strings.Join(fragments, "/"), nil
+}
+
 func (r *Route) use(mm ...Middleware) {
 	if r.middlewarelist == nil {
 		r.middlewarelist = make([]Middleware, 0, len(mm))
@@ -206,31 +268,55 @@ func (r *Route) use(mm ...Middleware) {
 	r.middlewarelist = append(r.middlewarelist, mm...)
 }
 
-func routeServeChainedHandlers(r *Route) http.HandlerFunc {
+func routeServeChainedHandlers(r *Route, handlers []http.HandlerFunc) http.HandlerFunc {
 	return func(rw http.ResponseWriter, req *http.Request) {
 
 		crw, ok := rw.(*customResponseWriter)
 		if !ok {
 			crw = newCRW(rw, http.StatusOK)
+			defer releaseCRW(crw)
+			// if rw wraps webgo's response writer, the router & request are retained so that
+			// the router's configurations are respected
+			if inner := findCRW(rw); inner != nil {
+				crw.router = inner.router
+				crw.req = inner.req
+			}
 		}
 
-		for _, handler := range r.Handlers {
+		for _, handler := range handlers {
 			if crw.written && !r.FallThroughPostResponse {
 				break
 			}
 			handler(crw, req)
 		}
+
+		respondError(crw, req)
+	}
+}
+
+// respondError responds with the error set by the handler(s) using the ErrorHandler, only if none
+// of them have responded to the client. It's done before returning to the middleware of the route,
+// so that they see the final response, e.g. the status code
+func respondError(crw *customResponseWriter, req *http.Request) {
+	cp := webgoContext(req)
+	if cp == nil || cp.Err == nil || crw.headerWritten {
+		return
 	}
+	HandleError(crw, req, cp.Err)
 }
 
 func defaultRouteServe(r *Route) http.HandlerFunc {
-	if len(r.Handlers) > 1 {
-		return routeServeChainedHandlers(r)
+	// a new slice is used, so that the Handlers of the route (which may share its backing array
+	// with other routes) are not modified
+	handlers := make([]http.HandlerFunc, 0, len(r.Handlers)+len(r.HandlersE))
+	handlers = append(handlers, r.Handlers...)
+	for _, hfe := range r.HandlersE {
+		handlers = append(handlers, hfe.ServeHTTP)
 	}
 
-	// when there is only 1 handler, custom response writer is not required to check if response
-	// is already written or fallthrough is enabled
-	return r.Handlers[0]
+	// the custom response writer is required even for a single handler, to check if the response
+	// is already written before responding with the error set by it
+	return routeServeChainedHandlers(r, handlers)
 }
 
 type RouteGroup struct {
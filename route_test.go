@@ -1,6 +1,7 @@
 package webgo
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -239,3 +240,33 @@ func TestMatchWithWildcard(t *testing.T) {
 		}
 	})
 }
+
+func TestRouterURL(t *testing.T) {
+	t.Parallel()
+	router := NewRouter(&Config{},
+		&Route{Name: "user", Method: http.MethodGet, Pattern: "/users/:userID", Handlers: []http.HandlerFunc{dummyHandler}},
+		&Route{Name: "files", Method: http.MethodPost, Pattern: "/files/:path*/meta", Handlers: []http.HandlerFunc{dummyHandler}},
+	)
+
+	tests := []struct {
+		name    string
+		route   string
+		params  map[string]string
+		want    string
+		wantErr error
+	}{
+		{name: "named param", route: "user", params: map[string]string{"userID": "a b"}, want: "/users/a%20b"},
+		{name: "wildcard", route: "files", params: map[string]string{"path": "a/b c"}, want: "/files/a/b%20c/meta"},
+		{name: "missing param", route: "user", wantErr: ErrMissingURIParam},
+		{name: "missing route", route: "unknown", wantErr: ErrRouteNotFound},
+	}
+	for _, tt := range tests {
+		got, err := router.URL(tt.route, tt.params)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error '%v', got '%v'", tt.name, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.want, got)
+		}
+	}
+}
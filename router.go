@@ -2,12 +2,15 @@ package webgo
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"sync"
+	"time"
 )
 
 // httpResponseWriter has all the functions to be implemented by the custom
@@ -18,6 +21,8 @@ type httpResponseWriter interface {
 	http.Flusher
 	http.Hijacker
 	http.Pusher
+	io.ReaderFrom
+	Unwrap() http.ResponseWriter
 }
 
 func init() {
@@ -47,6 +52,11 @@ var (
 			return new(customResponseWriter)
 		},
 	}
+	bufferPool = &sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
 )
 
 // customResponseWriter is a custom HTTP response writer
@@ -55,6 +65,24 @@ type customResponseWriter struct {
 	statusCode    int
 	written       bool
 	headerWritten bool
+
+	// bytesWritten is the number of bytes of the response body written
+	bytesWritten int64
+	// start is the time when the custom response writer was created, and firstByte
+	// is the time when the response header was written
+	start     time.Time
+	firstByte time.Time
+
+	// buffered if true, the response is held in buffer till it is flushed, or its size exceeds
+	// bufferLimit. After which the response is written directly to the underlying response writer
+	buffered    bool
+	buffer      *bytes.Buffer
+	bufferLimit int64
+
+	// router & req are the router serving the request and the request itself. They are
+	// available only if the request is being served by a webgo router
+	router *Router
+	req    *http.Request
 }
 
 // WriteHeader is the interface implementation to get HTTP response code and add
@@ -66,6 +94,10 @@ func (crw *customResponseWriter) WriteHeader(code int) {
 
 	crw.headerWritten = true
 	crw.statusCode = code
+	if crw.buffered {
+		return
+	}
+	crw.firstByte = time.Now()
 	crw.ResponseWriter.WriteHeader(code)
 }
 
@@ -74,11 +106,103 @@ func (crw *customResponseWriter) WriteHeader(code int) {
 func (crw *customResponseWriter) Write(body []byte) (int, error) {
 	crw.WriteHeader(crw.statusCode)
 	crw.written = true
-	return crw.ResponseWriter.Write(body)
+
+	if crw.buffered {
+		if int64(crw.buffer.Len()+len(body)) <= crw.bufferLimit {
+			return crw.buffer.Write(body)
+		}
+		// the response is too large to be held in memory, so it is streamed from here on
+		err := crw.flushBuffer()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := crw.ResponseWriter.Write(body)
+	crw.bytesWritten += int64(n)
+	return n, err
+}
+
+// bufferResponse makes the response writer hold the response in memory, till flushBuffer is
+// called or the size of the response body exceeds limit
+func (crw *customResponseWriter) bufferResponse(limit int64) {
+	if crw.buffered || crw.headerWritten {
+		return
+	}
+	crw.buffered = true
+	crw.bufferLimit = limit
+	crw.buffer = bufferPool.Get().(*bytes.Buffer)
+}
+
+// flushBuffer writes the buffered response header & body to the underlying response writer, and
+// disables buffering
+func (crw *customResponseWriter) flushBuffer() error {
+	if !crw.buffered {
+		return nil
+	}
+	crw.buffered = false
+	if !crw.headerWritten {
+		return nil
+	}
+
+	crw.firstByte = time.Now()
+	crw.ResponseWriter.WriteHeader(crw.statusCode)
+	if crw.buffer.Len() == 0 {
+		return nil
+	}
+
+	n, err := crw.ResponseWriter.Write(crw.buffer.Bytes())
+	crw.bytesWritten += int64(n)
+	crw.buffer.Reset()
+	return err
 }
 
-// Flush calls the http.Flusher to clear/flush the buffer
+// discardBuffer discards the buffered response, so that a different response can be written. It
+// returns false if the response is not buffered, i.e. it may have already been sent to the client
+func (crw *customResponseWriter) discardBuffer() bool {
+	if !crw.buffered {
+		return false
+	}
+	crw.buffer.Reset()
+	crw.headerWritten = false
+	crw.written = false
+	return true
+}
+
+// ReadFrom implements the io.ReaderFrom interface, so that the underlying response writer's
+// optimizations (e.g. sendfile) are retained when copying a file to the response
+func (crw *customResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if crw.buffered {
+		// the anonymous struct hides ReadFrom, so that io.Copy uses Write of the response writer
+		return io.Copy(struct{ io.Writer }{crw}, src)
+	}
+
+	crw.WriteHeader(crw.statusCode)
+	crw.written = true
+
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := crw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		// the anonymous struct hides all methods of the response writer other than Write
+		n, err = io.Copy(struct{ io.Writer }{crw.ResponseWriter}, src)
+	}
+	crw.bytesWritten += n
+	return n, err
+}
+
+// Unwrap returns the underlying response writer, it is used by http.ResponseController
+func (crw *customResponseWriter) Unwrap() http.ResponseWriter {
+	return crw.ResponseWriter
+}
+
+// Flush calls the http.Flusher to clear/flush the buffer. A buffered response is written to the
+// underlying response writer, and is not buffered anymore
 func (crw *customResponseWriter) Flush() {
+	_ = crw.flushBuffer()
 	if rw, ok := crw.ResponseWriter.(http.Flusher); ok {
 		rw.Flush()
 	}
@@ -112,7 +236,20 @@ func (crw *customResponseWriter) reset() {
 	crw.statusCode = 0
 	crw.written = false
 	crw.headerWritten = false
+	crw.bytesWritten = 0
+	crw.start = time.Time{}
+	crw.firstByte = time.Time{}
+	// large buffers are not pooled, so that a few large responses do not hold on to memory
+	if crw.buffer != nil && crw.buffer.Cap() <= maxPooledBufferSize {
+		crw.buffer.Reset()
+		bufferPool.Put(crw.buffer)
+	}
+	crw.buffered = false
+	crw.buffer = nil
+	crw.bufferLimit = 0
 	crw.ResponseWriter = nil
+	crw.router = nil
+	crw.req = nil
 }
 
 // Middleware is the signature of WebGo's middleware
@@ -145,6 +282,33 @@ type Router struct {
 	// NotImplemented is the generic handler for 501 method not implemented
 	NotImplemented http.HandlerFunc
 
+	// ErrorHandler is used to respond to the client, when a HandlerFuncE returns an error
+	// or when an error is set using SetError without responding to the client
+	ErrorHandler ErrorHandler
+
+	// ProblemDetails if set, all error responses (SendError & the Rxxx helpers which use it)
+	// are RFC 9457 problem details, instead of the `{errors: <errors>, status: <int>}` struct
+	ProblemDetails *ProblemDetailsConfig
+
+	// DataEnvelope builds the response body of SendResponse & the Rxxx helpers which use it.
+	// Default is `{data: <payload>, status: <int>}`
+	DataEnvelope Envelope
+	// ErrorEnvelope builds the response body of SendError & the Rxxx helpers which use it.
+	// Default is `{errors: <payload>, status: <int>}`
+	ErrorEnvelope Envelope
+
+	// Views are used to render HTML templates using View
+	Views *Views
+
+	// RedirectAllowedHosts are the hosts, other than the host of the request, to which Redirect
+	// can redirect the client. "*" allows all hosts, which disables the open redirect check
+	RedirectAllowedHosts []string
+
+	// ETag if set, enables ETags & conditional GET (304 Not Modified) for all the GET & HEAD routes
+	ETag *ETagConfig
+	// errMappings has all the domain errors registered using MapError
+	errMappings []errMapping
+
 	// config has all the app config
 	config *Config
 
@@ -181,13 +345,16 @@ func (rtr *Router) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	// encoding errors. i.e. if there's a JSON encoding issue while responding,
 	// the HTTP status code would say 200, and and the JSON payload {"status": 500}
 	crw := newCRW(rw, http.StatusOK)
+	crw.router = rtr
+	crw.req = r
 
 	routes := rtr.methodRoutes(r.Method)
 	if routes == nil {
-		// serve 501 when HTTP method is not implemented
+		// serve 501 when HTTP method is not implemented. The response writer is released using
+		// defer, so that it's returned to the pool even if the handler panics
+		defer releaseCRW(crw)
 		crw.statusCode = http.StatusNotImplemented
 		rtr.NotImplemented(crw, r)
-		releaseCRW(crw)
 		return
 	}
 
@@ -195,15 +362,16 @@ func (rtr *Router) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	route, params := discoverRoute(path, routes)
 	if route == nil {
 		// serve 404 when there are no matching routes
+		defer releaseCRW(crw)
 		crw.statusCode = http.StatusNotFound
 		rtr.NotFound(crw, r)
-		releaseCRW(crw)
 		return
 	}
 
 	ctxPayload := newContext()
 	ctxPayload.Route = route
 	ctxPayload.URIParams = params
+	ctxPayload.router = rtr
 
 	// webgo context is injected to the HTTP request context
 	*r = *r.WithContext(
@@ -214,8 +382,41 @@ func (rtr *Router) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		),
 	)
 
+	bufferSize := route.BufferSize
+	etag := rtr.etagConfig(route, r.Method)
+	if etag != nil {
+		limit := etag.MaxSize
+		if limit <= 0 {
+			limit = defaultETagMaxSize
+		}
+		if limit > bufferSize {
+			bufferSize = limit
+		}
+	}
+	if bufferSize > 0 {
+		crw.bufferResponse(bufferSize)
+	}
+
 	defer releasePoolResources(crw, ctxPayload)
 	route.serve(crw, r)
+
+	// errors set by the handler(s) are responded within the route (refer respondError). An error
+	// set by a middleware is responded using the ErrorHandler, only if none of them have
+	// responded to the client
+	if ctxPayload.Err != nil && !crw.headerWritten {
+		HandleError(crw, r, ctxPayload.Err)
+	}
+
+	if etag != nil {
+		setETag(crw, r, etag)
+	}
+
+	// a buffered response is written to the client only after all the handlers & middleware
+	// are executed
+	err := crw.flushBuffer()
+	if err != nil {
+		RequestLogger(r).Error(err)
+	}
 }
 
 // Use adds a middleware layer
@@ -289,10 +490,32 @@ func (rtr *Router) Add(routes ...*Route) {
 	rtr.allHandlers = all
 }
 
+// Route returns the route with the given name, or nil if there's no such route
+func (rtr *Router) Route(name string) *Route {
+	for _, method := range supportedHTTPMethods {
+		for _, route := range rtr.allHandlers[method] {
+			if route.Name == name {
+				return route
+			}
+		}
+	}
+	return nil
+}
+
+// URL returns the URI path of the route with the given name, refer Route.URL
+func (rtr *Router) URL(name string, params map[string]string) (string, error) {
+	route := rtr.Route(name)
+	if route == nil {
+		return "", fmt.Errorf("%w: '%s'", ErrRouteNotFound, name)
+	}
+	return route.URL(params)
+}
+
 func newCRW(rw http.ResponseWriter, rCode int) *customResponseWriter {
 	crw := crwPool.Get().(*customResponseWriter)
 	crw.ResponseWriter = rw
 	crw.statusCode = rCode
+	crw.start = time.Now()
 	return crw
 }
 
@@ -322,7 +545,8 @@ func NewRouter(cfg *Config, routes ...*Route) *Router {
 		NotImplemented: func(rw http.ResponseWriter, req *http.Request) {
 			Send(rw, "", "501 Not Implemented", http.StatusNotImplemented)
 		},
-		config: cfg,
+		ErrorHandler: DefaultErrorHandler,
+		config:       cfg,
 	}
 
 	r.Add(routes...)
@@ -391,7 +615,7 @@ func httpHandlers(routes []*Route) map[string][]*Route {
 			return nil
 		}
 
-		if route.Handlers == nil || len(route.Handlers) == 0 {
+		if len(route.Handlers) == 0 && len(route.HandlersE) == 0 {
 			LOGHANDLER.Fatal(
 				fmt.Sprintf(
 					"No handlers provided for the route '%s', method '%s'",
@@ -716,3 +716,69 @@ func TestWildcardMadness(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestBufferedResponse(t *testing.T) {
+	t.Parallel()
+	router := NewRouter(&Config{},
+		&Route{
+			Name:       "buffered",
+			Method:     http.MethodGet,
+			Pattern:    "/buffered",
+			BufferSize: 1024,
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					rd, _ := ResponseInfo(w)
+					if !rd.Buffered {
+						t.Error("expected the response to be buffered")
+					}
+					_, _ = w.Write([]byte("partial"))
+					if !DiscardResponse(w) {
+						t.Error("expected the buffered response to be discarded")
+					}
+					R400(w, "bad request")
+				},
+			},
+		},
+		&Route{
+			Name:       "large",
+			Method:     http.MethodGet,
+			Pattern:    "/large",
+			BufferSize: 10,
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+					if DiscardResponse(w) {
+						t.Error("expected the streamed response to not be discarded")
+					}
+				},
+			},
+		},
+	)
+	router.Use(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(w, r)
+		// header is set after the handler has responded
+		w.Header().Set("X-After", "true")
+	})
+	router.SetupMiddleware()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/buffered", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status '%d', got '%d'", http.StatusBadRequest, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "partial") {
+		t.Errorf("expected the partial response to be discarded, got '%s'", w.Body.String())
+	}
+	if w.Result().Header.Get("X-After") != "true" {
+		t.Error("expected the header set by middleware after the handler, to be sent")
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/large", nil))
+	if w.Body.Len() != 100 {
+		t.Errorf("expected 100 bytes, got '%d'", w.Body.Len())
+	}
+	if w.Result().Header.Get("X-After") != "" {
+		t.Error("expected the header set after a streamed response, to not be sent")
+	}
+}
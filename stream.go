@@ -0,0 +1,194 @@
+package webgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// NDJSONContentType is the MIME type of newline delimited JSON
+	NDJSONContentType = "application/x-ndjson"
+
+	// defaultFlushEvery is the default number of items after which a stream is flushed
+	defaultFlushEvery = 100
+)
+
+// ErrStreamClosed is returned when trying to encode an item after the stream is closed
+var ErrStreamClosed = errors.New("stream is already closed")
+
+// StreamFormat is the format in which the items of a JSONStream are written
+type StreamFormat int
+
+const (
+	// StreamJSONArray streams the items as a JSON array within the standard
+	// `{data: [<items>], status: <int>}` struct
+	StreamJSONArray StreamFormat = iota
+	// StreamNDJSON streams each item as JSON on a new line (https://github.com/ndjson/ndjson-spec)
+	StreamNDJSON
+)
+
+// JSONStream writes items one at a time, so that large lists need not be held in memory
+type JSONStream struct {
+	// Format is the format of the stream, default is StreamJSONArray
+	Format StreamFormat
+	// FlushEvery is the number of items after which the response is flushed to the client
+	FlushEvery int
+
+	w http.ResponseWriter
+	r *http.Request
+	// buf has the compacted JSON of the item being written, and raw has it as encoded by the codec
+	buf     *bytes.Buffer
+	raw     *bytes.Buffer
+	count   int
+	started bool
+	closed  bool
+}
+
+// StreamJSON returns a JSONStream to respond with a large number of items. Format & FlushEvery
+// can be changed before encoding the first item. Nothing is written to w until the first item is
+// encoded, so an error before that can still be responded normally when closing the stream.
+// Envelopes configured on the router are not applied to streams
+func StreamJSON(w http.ResponseWriter, r *http.Request) *JSONStream {
+	return &JSONStream{
+		Format:     StreamJSONArray,
+		FlushEvery: defaultFlushEvery,
+		w:          w,
+		r:          r,
+		buf:        bytes.NewBuffer(nil),
+		raw:        bytes.NewBuffer(nil),
+	}
+}
+
+// encode encodes v into buf using the codec. The JSON is compacted irrespective of the codec's
+// configuration (e.g. JSONCodec.Indent), since every item of NDJSON should be on a single line
+func (js *JSONStream) encode(v interface{}) error {
+	js.raw.Reset()
+	err := codec.Encode(js.raw, v)
+	if err != nil {
+		return err
+	}
+
+	js.buf.Reset()
+	return json.Compact(js.buf, js.raw.Bytes())
+}
+
+func (js *JSONStream) start() {
+	js.started = true
+	if js.Format == StreamNDJSON {
+		js.w.Header().Set(HeaderContentType, NDJSONContentType)
+		SendHeader(js.w, http.StatusOK)
+		return
+	}
+
+	js.w.Header().Set(HeaderContentType, JSONContentType)
+	SendHeader(js.w, http.StatusOK)
+	_, _ = js.w.Write([]byte(`{"data":[`))
+}
+
+func (js *JSONStream) flush() {
+	if flusher, ok := js.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Encode writes the item to the stream. It returns an error if the client has disconnected,
+// in which case the stream should be abandoned
+func (js *JSONStream) Encode(item interface{}) error {
+	if js.closed {
+		return ErrStreamClosed
+	}
+
+	err := js.r.Context().Err()
+	if err != nil {
+		js.closed = true
+		return err
+	}
+
+	err = js.encode(item)
+	if err != nil {
+		return err
+	}
+	if js.Format == StreamNDJSON {
+		js.buf.WriteByte('\n')
+	}
+
+	if !js.started {
+		js.start()
+	}
+
+	if js.Format == StreamJSONArray && js.count > 0 {
+		_, err = js.w.Write([]byte(`,`))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = js.w.Write(js.buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	js.count++
+	if js.FlushEvery > 0 && js.count%js.FlushEvery == 0 {
+		js.flush()
+	}
+
+	return nil
+}
+
+// Close completes the stream. If err is not nil and no items were written yet, the error is
+// responded using the router's ErrorHandler. Otherwise the error is written at the end of the
+// stream, as `{errors: <error>, status: <int>}` for NDJSON, or as the `errors` & `status` keys
+// of the JSON array struct. Only HTTPErrors are exposed to the client, refer AsHTTPError
+func (js *JSONStream) Close(err error) error {
+	if js.closed {
+		return nil
+	}
+	js.closed = true
+
+	if err != nil && !js.started {
+		HandleError(js.w, js.r, err)
+		return nil
+	}
+
+	if !js.started {
+		js.start()
+	}
+
+	var herr *HTTPError
+	if err != nil {
+		if cp := webgoContext(js.r); cp != nil {
+			cp.SetError(err)
+		}
+		herr = AsHTTPError(js.r, err)
+	}
+
+	var werr error
+	switch {
+	case js.Format == StreamNDJSON && herr != nil:
+		werr = js.encode(errOutput{herr, herr.Status})
+		if werr == nil {
+			js.buf.WriteByte('\n')
+			_, werr = js.w.Write(js.buf.Bytes())
+		}
+	case js.Format == StreamJSONArray && herr != nil:
+		werr = js.encode(herr)
+		if werr == nil {
+			_, werr = js.w.Write([]byte(`],"errors":`))
+		}
+		if werr == nil {
+			_, werr = js.w.Write(js.buf.Bytes())
+		}
+		if werr == nil {
+			_, werr = js.w.Write([]byte(`,"status":` + strconv.Itoa(herr.Status) + "}\n"))
+		}
+	case js.Format == StreamJSONArray:
+		_, werr = js.w.Write([]byte(`],"status":` + strconv.Itoa(http.StatusOK) + "}\n"))
+	}
+
+	js.flush()
+	return werr
+}
@@ -0,0 +1,140 @@
+package webgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamJSON(t *testing.T) {
+	t.Parallel()
+
+	// JSON array
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	stream := StreamJSON(w, r)
+	stream.FlushEvery = 2
+	for i := 0; i < 5; i++ {
+		err := stream.Encode(map[string]int{"id": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := stream.Close(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.Flushed {
+		t.Error("expected response to be flushed")
+	}
+
+	out := struct {
+		Data   []map[string]int
+		Status int
+	}{}
+	err = json.Unmarshal(w.Body.Bytes(), &out)
+	if err != nil {
+		t.Fatalf("%v, raw response: '%s'", err, w.Body.String())
+	}
+	if len(out.Data) != 5 || out.Data[4]["id"] != 4 || out.Status != http.StatusOK {
+		t.Errorf("unexpected response '%s'", w.Body.String())
+	}
+
+	// JSON array with a trailing error
+	w = httptest.NewRecorder()
+	stream = StreamJSON(w, r)
+	_ = stream.Encode("a")
+	_ = stream.Close(NewHTTPError(http.StatusServiceUnavailable, "db_down", ""))
+	errOut := struct {
+		Data   []string
+		Errors HTTPError
+		Status int
+	}{}
+	err = json.Unmarshal(w.Body.Bytes(), &errOut)
+	if err != nil {
+		t.Fatalf("%v, raw response: '%s'", err, w.Body.String())
+	}
+	if errOut.Status != http.StatusServiceUnavailable || errOut.Errors.Code != "db_down" || len(errOut.Data) != 1 {
+		t.Errorf("unexpected response '%s'", w.Body.String())
+	}
+
+	// NDJSON with a trailing error, which should not be exposed
+	w = httptest.NewRecorder()
+	stream = StreamJSON(w, r)
+	stream.Format = StreamNDJSON
+	_ = stream.Encode("a")
+	_ = stream.Encode("b")
+	_ = stream.Close(errors.New("secret"))
+	if ctype := w.Header().Get(HeaderContentType); ctype != NDJSONContentType {
+		t.Errorf("expected content type '%s', got '%s'", NDJSONContentType, ctype)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || lines[0] != `"a"` || lines[1] != `"b"` {
+		t.Fatalf("unexpected response '%s'", w.Body.String())
+	}
+	if strings.Contains(lines[2], "secret") || !strings.Contains(lines[2], `"status":500`) {
+		t.Errorf("unexpected error line '%s'", lines[2])
+	}
+
+	// error before any items are written
+	w = httptest.NewRecorder()
+	stream = StreamJSON(w, r)
+	_ = stream.Close(NewHTTPError(http.StatusNotFound, "", ""))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestStreamJSONClientDisconnect(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	stream := StreamJSON(w, r)
+	err := stream.Encode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	err = stream.Encode(2)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error '%v', got '%v'", context.Canceled, err)
+	}
+	err = stream.Encode(3)
+	if !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("expected error '%v', got '%v'", ErrStreamClosed, err)
+	}
+}
+
+func TestStreamJSONIndentedCodec(t *testing.T) {
+	// not parallel, since the codec is global
+	SetCodec(&JSONCodec{Indent: "\t"})
+	defer SetCodec(nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	stream := StreamJSON(w, r)
+	stream.Format = StreamNDJSON
+	_ = stream.Encode(map[string]int{"id": 1})
+	_ = stream.Encode(map[string]int{"id": 2})
+	_ = stream.Close(NewHTTPError(http.StatusServiceUnavailable, "db_down", ""))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || lines[0] != `{"id":1}` || lines[1] != `{"id":2}` {
+		t.Fatalf("expected every item on a single line, got '%s'", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	stream = StreamJSON(w, r)
+	_ = stream.Encode(map[string]int{"id": 1})
+	_ = stream.Encode(map[string]int{"id": 2})
+	_ = stream.Close(nil)
+	if want := `{"data":[{"id":1},{"id":2}],"status":200}` + "\n"; w.Body.String() != want {
+		t.Errorf("expected '%s', got '%s'", want, w.Body.String())
+	}
+}
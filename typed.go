@@ -0,0 +1,104 @@
+package webgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// TypedOptions are the configurations available for a Typed handler
+type TypedOptions struct {
+	// Status is the HTTP status code used when the handler succeeds. Default is 200
+	Status int
+	// Encoder is used to respond with the value returned by the handler. Default is SendResponse,
+	// which wraps the value in the standard `{data: <data>, status: <int>}` struct. Respond can
+	// be used to respond in the format negotiated using the Accept header
+	Encoder func(w http.ResponseWriter, r *http.Request, data interface{}, rCode int)
+}
+
+func defaultTypedEncoder(w http.ResponseWriter, r *http.Request, data interface{}, rCode int) {
+	SendResponse(w, data, rCode)
+}
+
+// Typed converts fn into an http.HandlerFunc. The request is bound to a new instance of Req using
+// Bind, and is validated if it implements Validator. The value returned by fn is responded using
+// the Encoder in opts. Any error, including binding & validation errors, are responded using the
+// router's ErrorHandler.
+//
+// e.g. webgo.Typed(func(ctx context.Context, req GetUser) (*User, error){...}, nil)
+func Typed[Req, Resp any](fn func(context.Context, Req) (Resp, error), opts *TypedOptions) http.HandlerFunc {
+	status := http.StatusOK
+	encoder := defaultTypedEncoder
+	if opts != nil {
+		if opts.Status != 0 {
+			status = opts.Status
+		}
+		if opts.Encoder != nil {
+			encoder = opts.Encoder
+		}
+	}
+
+	hfe := HandlerFuncE(func(w http.ResponseWriter, r *http.Request) error {
+		req, err := bindTyped[Req](r)
+		if err != nil {
+			return err
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			return err
+		}
+
+		if status == http.StatusNoContent {
+			SendHeader(w, status)
+			return nil
+		}
+
+		encoder(w, r, resp, status)
+		return nil
+	})
+
+	return hfe.ServeHTTP
+}
+
+// bindTyped returns a new instance of Req, bound & validated using the request
+func bindTyped[Req any](r *http.Request) (Req, error) {
+	var req Req
+
+	target := reflect.ValueOf(&req)
+	if target.Elem().Kind() == reflect.Ptr {
+		// if Req is a pointer, then bind to a newly allocated value it points to
+		target.Elem().Set(reflect.New(target.Elem().Type().Elem()))
+		target = target.Elem()
+	}
+
+	if target.Elem().Kind() == reflect.Struct {
+		err := Bind(r, target.Interface())
+		if err != nil {
+			return req, err
+		}
+	}
+
+	v, ok := interface{}(req).(Validator)
+	if !ok {
+		return req, nil
+	}
+
+	err := v.Validate()
+	if err == nil {
+		return req, nil
+	}
+
+	var herr *HTTPError
+	if errors.As(err, &herr) {
+		return req, err
+	}
+
+	return req, &HTTPError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "validation_failed",
+		Message: err.Error(),
+		Cause:   err,
+	}
+}
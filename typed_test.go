@@ -0,0 +1,141 @@
+package webgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type typedUserReq struct {
+	ID      int      `param:"id"`
+	Fields  []string `query:"fields"`
+	Verbose *bool    `query:"verbose"`
+	Name    string   `json:"name"`
+}
+
+func (tur typedUserReq) Validate() error {
+	if tur.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type typedUserResp struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Fields  []string `json:"fields"`
+	Verbose bool     `json:"verbose"`
+}
+
+func TestTyped(t *testing.T) {
+	t.Parallel()
+	handler := Typed(
+		func(ctx context.Context, req typedUserReq) (*typedUserResp, error) {
+			if req.ID == 0 {
+				return nil, NewHTTPError(http.StatusNotFound, "not_found", "")
+			}
+			return &typedUserResp{
+				ID:      req.ID,
+				Name:    req.Name,
+				Fields:  req.Fields,
+				Verbose: req.Verbose != nil && *req.Verbose,
+			}, nil
+		},
+		&TypedOptions{Status: http.StatusCreated},
+	)
+
+	router := NewRouter(&Config{}, &Route{
+		Name:     "typed",
+		Method:   http.MethodPost,
+		Pattern:  "/users/:id",
+		Handlers: []http.HandlerFunc{handler},
+	})
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		ctype      string
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			url:        "/users/10?fields=a&fields=b&verbose=true",
+			body:       `{"name":"gopher"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid param",
+			url:        "/users/abc",
+			body:       `{"name":"gopher"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid query",
+			url:        "/users/10?verbose=maybe",
+			body:       `{"name":"gopher"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			url:        "/users/10",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported content type",
+			url:        "/users/10",
+			body:       `name=gopher`,
+			ctype:      "application/x-www-form-urlencoded",
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "validation failed",
+			url:        "/users/10",
+			body:       `{}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "handler error",
+			url:        "/users/0",
+			body:       `{"name":"gopher"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+		if tt.ctype != "" {
+			req.Header.Set(HeaderContentType, tt.ctype)
+		}
+		router.ServeHTTP(w, req)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d. Raw response: '%s'", tt.name, tt.wantStatus, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/users/10?fields=a&fields=b&verbose=true",
+		strings.NewReader(`{"name":"gopher"}`),
+	)
+	router.ServeHTTP(w, req)
+	resp := struct {
+		Data typedUserResp
+	}{}
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := typedUserResp{ID: 10, Name: "gopher", Fields: []string{"a", "b"}, Verbose: true}
+	if resp.Data.ID != want.ID || resp.Data.Name != want.Name || !resp.Data.Verbose ||
+		strings.Join(resp.Data.Fields, ",") != "a,b" {
+		t.Errorf("expected '%v', got '%v'", want, resp.Data)
+	}
+}
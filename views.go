@@ -0,0 +1,363 @@
+package webgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrViewNotFound is returned when there's no template for the view name
+	ErrViewNotFound = errors.New("view not found")
+	// ErrViewsNotConfigured is returned by View if Views are not set on the router
+	ErrViewsNotConfigured = errors.New("views are not configured on the router")
+)
+
+// ViewsConfig has all the configurations of Views
+type ViewsConfig struct {
+	// Extension of the template files, default is ".html"
+	Extension string
+	// LayoutsDir is the directory with all the layouts, default is "layouts"
+	LayoutsDir string
+	// PartialsDir is the directory with all the partials, which are shared by all the views.
+	// Default is "partials"
+	PartialsDir string
+	// Layout is the default layout used to render the views. e.g. "layouts/base". The layout
+	// should have blocks (e.g. {{block "content" .}}{{end}}), which are defined by the views
+	Layout string
+	// PageLayouts overrides the layout of specific views. An empty layout renders the view
+	// without any layout. e.g. {"users/export": ""}
+	PageLayouts map[string]string
+	// Funcs are added to all the templates, and can override the default functions
+	Funcs template.FuncMap
+	// AssetsPrefix is prefixed to the path provided to the `asset` function, default is "/static/"
+	AssetsPrefix string
+	// DevMode if true, reloads the templates if any of the files are changed. It should not be
+	// enabled in production
+	DevMode bool
+}
+
+// Views loads all the templates from an fs.FS, and renders them with layouts & partials. Names of
+// the templates are their paths relative to the root of the fs.FS, without the extension. e.g.
+// "users/show", "partials/nav". Following functions are available to all the templates:
+//   - url: URL of a named route, e.g. {{url "user" "userID" .ID}}
+//   - asset: path of a static asset, e.g. {{asset "css/main.css"}}
+//   - csrf_token, csrf_field: CSRF token & hidden form field, which should be provided by a CSRF
+//     middleware for the request using AddTemplateFuncs. They are empty otherwise
+//   - csp_nonce: nonce of the Content-Security-Policy, provided by a security headers middleware
+//     the same way. It's empty otherwise
+type Views struct {
+	fsys fs.FS
+	cfg  ViewsConfig
+
+	locker      sync.RWMutex
+	pages       map[string]*page
+	fingerprint uint64
+}
+
+// page is a parsed view. tpl is never executed, so that it can always be cloned to add the functions
+// specific to a request. An html template is escaped when it's executed first, so the clones with
+// only the router's functions (i.e. url) are retained per router, to not escape them every time
+type page struct {
+	tpl    *template.Template
+	clones sync.Map
+}
+
+// template returns the template to be executed for the request. It's a new clone only if the
+// request has its own functions (refer AddTemplateFuncs)
+func (p *page) template(r *http.Request) (*template.Template, error) {
+	cp := webgoContext(r)
+	if cp != nil && len(cp.templateFuncs) > 0 {
+		tpl, err := p.tpl.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return tpl.Funcs(requestFuncs(r)), nil
+	}
+
+	var rtr *Router
+	if cp != nil {
+		rtr = cp.router
+	}
+	if tpl, ok := p.clones.Load(rtr); ok {
+		return tpl.(*template.Template), nil
+	}
+
+	tpl, err := p.tpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tpl.Funcs(routerFuncs(rtr))
+	actual, _ := p.clones.LoadOrStore(rtr, tpl)
+	return actual.(*template.Template), nil
+}
+
+// NewViews loads all the templates from fsys and returns Views
+func NewViews(fsys fs.FS, cfg *ViewsConfig) (*Views, error) {
+	if cfg == nil {
+		cfg = &ViewsConfig{}
+	}
+
+	v := &Views{
+		fsys: fsys,
+		cfg:  *cfg,
+	}
+	if v.cfg.Extension == "" {
+		v.cfg.Extension = ".html"
+	}
+	if v.cfg.LayoutsDir == "" {
+		v.cfg.LayoutsDir = "layouts"
+	}
+	if v.cfg.PartialsDir == "" {
+		v.cfg.PartialsDir = "partials"
+	}
+	if v.cfg.AssetsPrefix == "" {
+		v.cfg.AssetsPrefix = "/static/"
+	}
+
+	fingerprint, err := v.fsFingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.load(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// funcs are the functions available to all templates while parsing. Request specific functions
+// are replaced while rendering
+func (v *Views) funcs() template.FuncMap {
+	funcs := template.FuncMap{
+		"url": func(name string, params ...string) (string, error) {
+			return "", ErrViewsNotConfigured
+		},
+		"asset": func(p string) string {
+			return v.cfg.AssetsPrefix + strings.TrimPrefix(p, "/")
+		},
+		"csrf_token": func() string {
+			return ""
+		},
+		"csrf_field": func() template.HTML {
+			return ""
+		},
+		"csp_nonce": func() string {
+			return ""
+		},
+	}
+	for name, fn := range v.cfg.Funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// files returns the names of all the template files, without the extension
+func (v *Views) files() ([]string, error) {
+	names := make([]string, 0)
+	err := fs.WalkDir(v.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != v.cfg.Extension {
+			return nil
+		}
+		names = append(names, strings.TrimSuffix(p, v.cfg.Extension))
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// fsFingerprint returns a hash of the names, sizes & modified time of all the files
+func (v *Views) fsFingerprint() (uint64, error) {
+	hash := fnv.New64a()
+	err := fs.WalkDir(v.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(hash, "%s:%d:%d;", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hash.Sum64(), err
+}
+
+func (v *Views) parse(tpl *template.Template, name string) error {
+	content, err := fs.ReadFile(v.fsys, name+v.cfg.Extension)
+	if err != nil {
+		return err
+	}
+	_, err = tpl.New(name).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("failed parsing template '%s': %w", name, err)
+	}
+	return nil
+}
+
+// load parses all the templates. Every view is parsed along with all the layouts & partials
+func (v *Views) load(fingerprint uint64) error {
+	names, err := v.files()
+	if err != nil {
+		return err
+	}
+
+	base := template.New("").Funcs(v.funcs())
+	pages := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, v.cfg.LayoutsDir+"/") && !strings.HasPrefix(name, v.cfg.PartialsDir+"/") {
+			pages = append(pages, name)
+			continue
+		}
+		err = v.parse(base, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	templates := make(map[string]*page, len(pages))
+	for _, name := range pages {
+		tpl, err := base.Clone()
+		if err != nil {
+			return err
+		}
+		err = v.parse(tpl, name)
+		if err != nil {
+			return err
+		}
+		templates[name] = &page{tpl: tpl}
+	}
+
+	v.locker.Lock()
+	v.pages = templates
+	v.fingerprint = fingerprint
+	v.locker.Unlock()
+
+	return nil
+}
+
+// reloadIfChanged reloads all the templates if any of the files have changed
+func (v *Views) reloadIfChanged() error {
+	fingerprint, err := v.fsFingerprint()
+	if err != nil {
+		return err
+	}
+
+	v.locker.RLock()
+	changed := fingerprint != v.fingerprint
+	v.locker.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	return v.load(fingerprint)
+}
+
+func (v *Views) layout(name string) string {
+	if layout, ok := v.cfg.PageLayouts[name]; ok {
+		return layout
+	}
+	return v.cfg.Layout
+}
+
+// routerFuncs returns the functions specific to the router serving the request
+func routerFuncs(rtr *Router) template.FuncMap {
+	return template.FuncMap{
+		"url": func(name string, params ...string) (string, error) {
+			if rtr == nil {
+				return "", ErrViewsNotConfigured
+			}
+			if len(params)%2 != 0 {
+				return "", fmt.Errorf("url: odd number of key-value parameters for route '%s'", name)
+			}
+			p := make(map[string]string, len(params)/2)
+			for i := 0; i < len(params); i += 2 {
+				p[params[i]] = params[i+1]
+			}
+			return rtr.URL(name, p)
+		},
+	}
+}
+
+// requestFuncs returns the functions specific to the request
+func requestFuncs(r *http.Request) template.FuncMap {
+	cp := webgoContext(r)
+	if cp == nil {
+		return nil
+	}
+
+	funcs := routerFuncs(cp.router)
+	for name, fn := range cp.templateFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// Execute executes the view with the given name, and writes the output to w
+func (v *Views) Execute(w io.Writer, r *http.Request, name string, data interface{}) error {
+	if v.cfg.DevMode {
+		err := v.reloadIfChanged()
+		if err != nil {
+			return err
+		}
+	}
+
+	v.locker.RLock()
+	pg, ok := v.pages[name]
+	v.locker.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: '%s'", ErrViewNotFound, name)
+	}
+
+	tpl, err := pg.template(r)
+	if err != nil {
+		return err
+	}
+
+	execName := name
+	if layout := v.layout(name); layout != "" {
+		execName = layout
+	}
+
+	return tpl.ExecuteTemplate(w, execName, data)
+}
+
+// Render renders the view with the given name as the HTML response
+func (v *Views) Render(w http.ResponseWriter, r *http.Request, name string, data interface{}, rCode int) {
+	buf := bytes.NewBuffer(nil)
+	err := v.Execute(buf, r, name, data)
+	if err != nil {
+		Send(w, TextContentType, ErrInternalServer, http.StatusInternalServerError)
+		RequestLogger(r).Error(err.Error())
+		return
+	}
+
+	Send(w, HTMLContentType, buf, rCode)
+}
+
+// View renders the view with the given name as the HTML response, using the Views of the router
+// serving the request
+func View(w http.ResponseWriter, r *http.Request, name string, data interface{}, rCode int) {
+	cp := webgoContext(r)
+	if cp == nil || cp.router == nil || cp.router.Views == nil {
+		Send(w, TextContentType, ErrInternalServer, http.StatusInternalServerError)
+		RequestLogger(r).Error(ErrViewsNotConfigured.Error())
+		return
+	}
+
+	cp.router.Views.Render(w, r, name, data, rCode)
+}
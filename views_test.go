@@ -0,0 +1,214 @@
+package webgo
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func viewsFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": &fstest.MapFile{
+			Data: []byte(`<html><head><link href="{{asset "css/main.css"}}"></head>` +
+				`<body>{{template "partials/nav" .}}{{block "content" .}}default{{end}}</body></html>`),
+		},
+		"partials/nav.html": &fstest.MapFile{
+			Data: []byte(`<nav>{{csrf_token}}</nav>`),
+		},
+		"users/show.html": &fstest.MapFile{
+			Data: []byte(`{{define "content"}}<a href="{{url "user" "userID" .ID}}">{{.Name}}</a>{{end}}`),
+		},
+		"users/plain.html": &fstest.MapFile{
+			Data: []byte(`plain {{.Name}}`),
+		},
+	}
+}
+
+func TestViews(t *testing.T) {
+	t.Parallel()
+	views, err := NewViews(viewsFS(), &ViewsConfig{
+		Layout:      "layouts/base",
+		PageLayouts: map[string]string{"users/plain": ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewRouter(&Config{},
+		&Route{
+			Name:    "user",
+			Method:  http.MethodGet,
+			Pattern: "/users/:userID",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					AddTemplateFuncs(r, template.FuncMap{
+						"csrf_token": func() string { return "token" },
+					})
+					View(w, r, "users/show", map[string]string{"ID": "a b", "Name": "<gopher>"}, http.StatusOK)
+				},
+			},
+		},
+		&Route{
+			Name:    "plain",
+			Method:  http.MethodGet,
+			Pattern: "/plain",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					View(w, r, "users/plain", map[string]string{"Name": "gopher"}, http.StatusAccepted)
+				},
+			},
+		},
+		&Route{
+			Name:    "missing",
+			Method:  http.MethodGet,
+			Pattern: "/missing",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					View(w, r, "users/missing", nil, http.StatusOK)
+				},
+			},
+		},
+	)
+	router.Views = views
+
+	tests := []struct {
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			url:        "/users/1",
+			wantStatus: http.StatusOK,
+			wantBody: `<html><head><link href="/static/css/main.css"></head>` +
+				`<body><nav>token</nav><a href="/users/a%20b">&lt;gopher&gt;</a></body></html>`,
+		},
+		{
+			url:        "/plain",
+			wantStatus: http.StatusAccepted,
+			wantBody:   `plain gopher`,
+		},
+		{
+			url:        "/missing",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   ErrInternalServer,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.wantStatus, w.Code)
+		}
+		if w.Body.String() != tt.wantBody {
+			t.Errorf("%s: expected body '%s', got '%s'", tt.url, tt.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestViewsDevMode(t *testing.T) {
+	t.Parallel()
+	fsys := viewsFS()
+	views, err := NewViews(fsys, &ViewsConfig{DevMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &strings.Builder{}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	err = views.Execute(out, r, "users/plain", map[string]string{"Name": "gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "plain gopher" {
+		t.Errorf("expected 'plain gopher', got '%s'", out.String())
+	}
+
+	fsys["users/plain.html"] = &fstest.MapFile{
+		Data:    []byte(`changed {{.Name}}`),
+		ModTime: time.Now(),
+	}
+	out.Reset()
+	err = views.Execute(out, r, "users/plain", map[string]string{"Name": "gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "changed gopher" {
+		t.Errorf("expected 'changed gopher', got '%s'", out.String())
+	}
+}
+
+func TestViewsTemplateReuse(t *testing.T) {
+	t.Parallel()
+	views, err := NewViews(viewsFS(), &ViewsConfig{Layout: "layouts/base"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter(&Config{},
+		&Route{
+			Name:    "user",
+			Method:  http.MethodGet,
+			Pattern: "/users/:userID",
+			Handlers: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Query().Get("csrf") != "" {
+						AddTemplateFuncs(r, template.FuncMap{
+							"csrf_token": func() string { return "token" },
+						})
+					}
+					View(w, r, "users/show", map[string]string{"ID": "1", "Name": "gopher"}, http.StatusOK)
+				},
+			},
+		},
+	)
+	router.Views = views
+
+	pg := views.pages["users/show"]
+	var tpl *template.Template
+	for _, url := range []string{"/users/1", "/users/1?csrf=1", "/users/1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if !strings.Contains(w.Body.String(), `<a href="/users/1">gopher</a>`) {
+			t.Errorf("%s: unexpected body '%s'", url, w.Body.String())
+		}
+		if want := strings.Contains(url, "csrf"); strings.Contains(w.Body.String(), "<nav>token</nav>") != want {
+			t.Errorf("%s: expected csrf token: %v, got '%s'", url, want, w.Body.String())
+		}
+
+		clone, ok := pg.clones.Load(router)
+		if !ok {
+			t.Fatalf("%s: expected the template to be retained for the router", url)
+		}
+		if tpl != nil && clone != tpl {
+			t.Errorf("%s: expected the retained template to be reused", url)
+		}
+		tpl = clone.(*template.Template)
+	}
+}
+
+func BenchmarkViews(b *testing.B) {
+	views, err := NewViews(viewsFS(), &ViewsConfig{Layout: "layouts/base"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	router := NewRouter(&Config{}, &Route{
+		Name:    "user",
+		Method:  http.MethodGet,
+		Pattern: "/users/:userID",
+		Handlers: []http.HandlerFunc{
+			func(w http.ResponseWriter, r *http.Request) {
+				View(w, r, "users/show", map[string]string{"ID": "1", "Name": "gopher"}, http.StatusOK)
+			},
+		},
+	})
+	router.Views = views
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	}
+}
@@ -15,7 +15,9 @@ package webgo
 import (
 	"context"
 	"crypto/tls"
+	"html/template"
 	"net/http"
+	"time"
 )
 
 var supportedHTTPMethods = []string{
@@ -38,6 +40,15 @@ type ContextPayload struct {
 	Route     *Route
 	Err       error
 	URIParams map[string]string
+
+	// router is the router which is serving the current request
+	router *Router
+	// templateFuncs are the template functions specific to the current request
+	templateFuncs template.FuncMap
+	// pagination is the pagination of the list response, refer Paginate
+	pagination *Pagination
+	// requestID is the ID of the request, refer SetRequestID
+	requestID string
 }
 
 // Params returns the URI parameters of the respective route
@@ -48,6 +59,10 @@ func (cp *ContextPayload) Params() map[string]string {
 func (cp *ContextPayload) reset() {
 	cp.Route = nil
 	cp.Err = nil
+	cp.router = nil
+	cp.templateFuncs = nil
+	cp.pagination = nil
+	cp.requestID = ""
 }
 
 // SetError sets the err within the context
@@ -60,30 +75,147 @@ func (cp *ContextPayload) Error() error {
 	return cp.Err
 }
 
-// Context returns the ContextPayload injected inside the HTTP request context
+// Context returns the ContextPayload injected inside the HTTP request context. It returns nil
+// if the request is not being served by a webgo route, e.g. special handlers
 func Context(r *http.Request) *ContextPayload {
-	return r.Context().Value(wgoCtxKey).(*ContextPayload)
+	return webgoContext(r)
+}
+
+// webgoContext returns the ContextPayload if available, unlike Context it does not panic
+// when the request is not served by webgo. e.g. special handlers
+func webgoContext(r *http.Request) *ContextPayload {
+	cp, _ := r.Context().Value(wgoCtxKey).(*ContextPayload)
+	return cp
 }
 
-// SetError is a helper function to set the error in webgo context
+// SetError is a helper function to set the error in webgo context. It does nothing if the request
+// is not being served by webgo, e.g. special handlers
 func SetError(r *http.Request, err error) {
-	ctx := Context(r)
+	ctx := webgoContext(r)
+	if ctx == nil {
+		return
+	}
 	ctx.SetError(err)
 }
 
+// SetRequestID sets the ID of the request, which is then added to the logs of RequestLogger.
+// It is set by the requestid middleware
+func SetRequestID(r *http.Request, id string) {
+	cp := webgoContext(r)
+	if cp == nil {
+		return
+	}
+	cp.requestID = id
+}
+
+// RequestID returns the ID of the request set using SetRequestID, empty if there's none
+func RequestID(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	cp := webgoContext(r)
+	if cp == nil {
+		return ""
+	}
+	return cp.requestID
+}
+
+// AddTemplateFuncs adds template functions specific to the request, e.g. a CSRF token. These are
+// available to all the templates rendered using View, for the current request. The functions should
+// also be declared (with the same signature) in ViewsConfig.Funcs, for the templates to be parsed
+func AddTemplateFuncs(r *http.Request, funcs template.FuncMap) {
+	cp := webgoContext(r)
+	if cp == nil {
+		return
+	}
+
+	if cp.templateFuncs == nil {
+		cp.templateFuncs = make(template.FuncMap, len(funcs))
+	}
+	for name, fn := range funcs {
+		cp.templateFuncs[name] = fn
+	}
+}
+
 // GetError is a helper function to get the error from webgo context
 func GetError(r *http.Request) error {
-	return Context(r).Error()
+	cp := webgoContext(r)
+	if cp == nil {
+		return nil
+	}
+	return cp.Error()
 }
 
-// ResponseStatus returns the response status code. It works only if the http.ResponseWriter
-// is not wrapped in another response writer before calling ResponseStatus
-func ResponseStatus(rw http.ResponseWriter) int {
-	crw, ok := rw.(*customResponseWriter)
-	if !ok {
-		return http.StatusOK
+// ResponseDetails has the details of the response written so far
+type ResponseDetails struct {
+	// Status is the HTTP response status code
+	Status int
+	// HeaderWritten is true if the response header is already sent to the client
+	HeaderWritten bool
+	// BytesWritten is the number of bytes of the response body written to the client
+	BytesWritten int64
+	// Buffered is true if the response is held in memory and not yet sent to the client, refer
+	// Route.BufferSize
+	Buffered bool
+	// TimeToFirstByte is the duration from the start of serving the request, till
+	// the response header was written
+	TimeToFirstByte time.Duration
+}
+
+// findCRW returns webgo's custom response writer, by unwrapping rw if it's wrapped by other
+// response writers. The wrappers should implement `Unwrap() http.ResponseWriter`, which is the
+// same convention as http.ResponseController
+func findCRW(rw http.ResponseWriter) *customResponseWriter {
+	for rw != nil {
+		switch w := rw.(type) {
+		case *customResponseWriter:
+			return w
+		case interface{ Unwrap() http.ResponseWriter }:
+			rw = w.Unwrap()
+		default:
+			return nil
+		}
 	}
-	return crw.statusCode
+	return nil
+}
+
+// ResponseInfo returns the details of the response written so far. It returns false if rw is
+// neither webgo's response writer, nor wraps it. i.e. the request is not being served by webgo
+func ResponseInfo(rw http.ResponseWriter) (ResponseDetails, bool) {
+	crw := findCRW(rw)
+	if crw == nil {
+		return ResponseDetails{Status: http.StatusOK}, false
+	}
+
+	rd := ResponseDetails{
+		Status:        crw.statusCode,
+		HeaderWritten: crw.headerWritten,
+		BytesWritten:  crw.bytesWritten,
+		Buffered:      crw.buffered,
+	}
+	if crw.headerWritten && !crw.start.IsZero() {
+		rd.TimeToFirstByte = crw.firstByte.Sub(crw.start)
+	}
+	return rd, true
+}
+
+// DiscardResponse discards the response written so far, if it is buffered (refer Route.BufferSize),
+// so that a different response can be written. e.g. to replace a partially written response with an
+// error. The response header is retained. It returns false if the response is not buffered, i.e.
+// it may have already been sent to the client
+func DiscardResponse(rw http.ResponseWriter) bool {
+	crw := findCRW(rw)
+	if crw == nil {
+		return false
+	}
+	return crw.discardBuffer()
+}
+
+// ResponseStatus returns the response status code. It works as long as the http.ResponseWriter
+// is webgo's response writer, or wraps it (refer ResponseInfo). Otherwise it returns 200
+func ResponseStatus(rw http.ResponseWriter) int {
+	rd, _ := ResponseInfo(rw)
+	return rd.Status
 }
 func (router *Router) setupServer() {
 	cfg := router.config
@@ -199,8 +331,8 @@ func (router *Router) ShutdownHTTPS() error {
 // OriginalResponseWriter returns the Go response writer stored within the webgo custom response
 // writer
 func OriginalResponseWriter(rw http.ResponseWriter) http.ResponseWriter {
-	crw, ok := rw.(*customResponseWriter)
-	if !ok {
+	crw := findCRW(rw)
+	if crw == nil {
 		return nil
 	}
 
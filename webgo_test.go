@@ -16,6 +16,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -45,6 +46,79 @@ func TestResponseStatus(t *testing.T) {
 	}
 }
 
+// wrapperWriter is a response writer wrapping another, like the ones used by third-party middleware
+type wrapperWriter struct {
+	http.ResponseWriter
+}
+
+func (ww *wrapperWriter) Unwrap() http.ResponseWriter {
+	return ww.ResponseWriter
+}
+
+func TestResponseInfo(t *testing.T) {
+	t.Parallel()
+	rec := httptest.NewRecorder()
+	crw := newCRW(rec, http.StatusOK)
+	w := &wrapperWriter{ResponseWriter: &wrapperWriter{ResponseWriter: crw}}
+
+	rd, ok := ResponseInfo(w)
+	if !ok {
+		t.Fatal("expected ResponseInfo to find the response writer")
+	}
+	if rd.HeaderWritten || rd.BytesWritten != 0 {
+		t.Errorf("expected nothing to be written, got %+v", rd)
+	}
+
+	SendError(w, "not found", http.StatusNotFound)
+	rd, _ = ResponseInfo(w)
+	if rd.Status != http.StatusNotFound {
+		t.Errorf("expected status '%d', got '%d'", http.StatusNotFound, rd.Status)
+	}
+	if ResponseStatus(w) != http.StatusNotFound {
+		t.Errorf("expected status '%d', got '%d'", http.StatusNotFound, ResponseStatus(w))
+	}
+	if !rd.HeaderWritten {
+		t.Error("expected header to be written")
+	}
+	if rd.BytesWritten != int64(rec.Body.Len()) {
+		t.Errorf("expected bytes written '%d', got '%d'", rec.Body.Len(), rd.BytesWritten)
+	}
+	if rd.TimeToFirstByte < 0 {
+		t.Errorf("expected non negative time to first byte, got '%s'", rd.TimeToFirstByte)
+	}
+	if OriginalResponseWriter(w) != rec {
+		t.Error("expected the original response writer to be the recorder")
+	}
+
+	_, ok = ResponseInfo(httptest.NewRecorder())
+	if ok {
+		t.Error("expected ResponseInfo to return false for a non webgo response writer")
+	}
+}
+
+func TestCRWReadFrom(t *testing.T) {
+	t.Parallel()
+	rec := httptest.NewRecorder()
+	crw := newCRW(rec, http.StatusAccepted)
+	n, err := crw.ReadFrom(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 11 || crw.bytesWritten != 11 {
+		t.Errorf("expected 11 bytes written, got '%d', '%d'", n, crw.bytesWritten)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected status '%d', got '%d'", http.StatusAccepted, rec.Code)
+	}
+	if rec.Body.String() != "hello world" {
+		t.Errorf("expected body 'hello world', got '%s'", rec.Body.String())
+	}
+
+	if crw.Unwrap() != rec {
+		t.Error("expected Unwrap to return the recorder")
+	}
+}
+
 func TestStart(t *testing.T) {
 	t.Parallel()
 	router, _ := setup(t, "9696")